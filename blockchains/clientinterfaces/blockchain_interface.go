@@ -10,6 +10,9 @@ import (
 	"diablo-benchmark/blockchains/workloadgenerators"
 	"diablo-benchmark/core/configs"
 	"diablo-benchmark/core/results"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // GenericInterface provides the required fields of the blockchain interface so that
@@ -22,11 +25,32 @@ type GenericInterface struct {
 	Success   uint64   // Number of successful transactions
 	Fail      uint64   // Number of failed transactions
 	Window    int      // Window to measure throughput
+
+	completionHandler func(success bool)          // Notified of every completed transaction (closed-loop mode)
+	observer          TransactionObserver         // Notified of the latency or error of every completed transaction (metrics)
+	compact           bool                        // Keep the latency histogram and error counts rather than the state of each transaction
+	compactLock       sync.Mutex                  // Lock on the latency histogram and error counts, written by the completing routines
+	latencies         *results.LatencyHistogram   // Latency of the committed transactions (compact mode)
+	errorCounts       map[results.ErrorClass]uint // Number of failed transactions per class (compact mode)
+}
+
+// TransactionObserver observes the transactions as they complete during the
+// benchmark, e.g. to expose them as metrics
+type TransactionObserver interface {
+	// ObserveCommit is notified of the latency (ms) of a committed transaction
+	ObserveCommit(latency float64)
+	// ObserveFailure is notified of the error class of a failed transaction
+	ObserveFailure(class results.ErrorClass)
 }
 
 // GetTxDone returns the number of transactions completed
 func (gi *GenericInterface) GetTxDone() uint64 {
-	return gi.NumTxDone
+	return atomic.LoadUint64(&gi.NumTxDone)
+}
+
+// GetTxFailed returns the number of completed transactions that failed
+func (gi *GenericInterface) GetTxFailed() uint64 {
+	return atomic.LoadUint64(&gi.Fail)
 }
 
 // SetWindow sets the window attribute of transactions
@@ -34,6 +58,90 @@ func (gi *GenericInterface) SetWindow(window int) {
 	gi.Window = window
 }
 
+// SetCompletionHandler sets the function notified whenever a transaction completes
+func (gi *GenericInterface) SetCompletionHandler(handler func(success bool)) {
+	gi.completionHandler = handler
+}
+
+// notifyCompletion notifies the completion handler (if any) that a transaction
+// was committed or failed
+func (gi *GenericInterface) notifyCompletion(success bool) {
+	if gi.completionHandler != nil {
+		gi.completionHandler(success)
+	}
+}
+
+// SetObserver sets the observer notified whenever a transaction completes
+func (gi *GenericInterface) SetObserver(observer TransactionObserver) {
+	gi.observer = observer
+}
+
+// SetCompact sets whether the client only keeps the latency histogram and the
+// error counts of the completed transactions, rather than the state of each
+// transaction, so that its memory does not grow with the benchmark.
+func (gi *GenericInterface) SetCompact(compact bool) {
+	gi.compact = compact
+	gi.latencies = results.NewLatencyHistogram(nil)
+	gi.errorCounts = make(map[results.ErrorClass]uint)
+}
+
+// compactResults returns the latency histogram and the error counts of the
+// completed transactions in compact mode, counting the given number of
+// uncommitted transactions as timeouts
+func (gi *GenericInterface) compactResults(timeouts uint) (*results.LatencyHistogram, map[results.ErrorClass]uint) {
+	gi.compactLock.Lock()
+	defer gi.compactLock.Unlock()
+
+	var counts map[results.ErrorClass]uint
+	for class, count := range gi.errorCounts {
+		if counts == nil {
+			counts = make(map[results.ErrorClass]uint)
+		}
+		counts[class] = count
+	}
+	if timeouts > 0 {
+		if counts == nil {
+			counts = make(map[results.ErrorClass]uint)
+		}
+		counts[results.ErrorTimeout] += timeouts
+	}
+
+	histogram := results.NewLatencyHistogram(nil)
+	histogram.Merge(gi.latencies)
+
+	return histogram, counts
+}
+
+// notifyCommit notifies the observer (if any) that a transaction sent at the
+// given time was committed at the other, and records its latency in compact mode
+func (gi *GenericInterface) notifyCommit(sent time.Time, committed time.Time) {
+	latency := float64(committed.Sub(sent)) / float64(time.Millisecond)
+
+	if gi.compact {
+		gi.compactLock.Lock()
+		gi.latencies.Record(latency)
+		gi.compactLock.Unlock()
+	}
+
+	if gi.observer != nil {
+		gi.observer.ObserveCommit(latency)
+	}
+}
+
+// notifyFailure notifies the observer (if any) that a transaction failed, and
+// counts its error in compact mode
+func (gi *GenericInterface) notifyFailure(class results.ErrorClass) {
+	if gi.compact {
+		gi.compactLock.Lock()
+		gi.errorCounts[class]++
+		gi.compactLock.Unlock()
+	}
+
+	if gi.observer != nil {
+		gi.observer.ObserveFailure(class)
+	}
+}
+
 // BlockchainInterface provides the basic funcitonality that will be tested
 // with the blockchains.
 // It _should_ cover most interaction, but will be extendible in the event that
@@ -89,6 +197,10 @@ type BlockchainInterface interface {
 	// This is already implemented with the GenericInterface
 	GetTxDone() uint64
 
+	// GetTxFailed returns the number of completed transactions that failed
+	// This is already implemented with the GenericInterface
+	GetTxFailed() uint64
+
 	// ParseBlocksForTransactions retrieves block information from start to end index and
 	// is used as a post-benchmark check to learn about the block and transactions.
 	ParseBlocksForTransactions(startNumber uint64, endNumber uint64) error
@@ -97,6 +209,27 @@ type BlockchainInterface interface {
 	// This is to be used for the throughput over time calculations.
 	SetWindow(window int)
 
+	// SetCompletionHandler sets the function notified whenever a transaction
+	// is committed or fails, used to bound the outstanding transactions.
+	// This is already implemented with the GenericInterface, implementations
+	// must call notifyCompletion when a transaction completes.
+	SetCompletionHandler(handler func(success bool))
+
+	// SetObserver sets the observer of the completed transactions.
+	// This is already implemented with the GenericInterface, implementations
+	// must call notifyCommit and notifyFailure when a transaction completes.
+	SetObserver(observer TransactionObserver)
+
+	// SetCompact sets whether the client only keeps the latency histogram and
+	// the error counts of the transactions, returned by Cleanup without the
+	// latencies and records of each transaction.
+	// This is already implemented with the GenericInterface, implementations
+	// must not keep the state of the completed transactions when compact.
+	SetCompact(compact bool)
+
+	// ClassifyError returns the class of the error a transaction failed with
+	ClassifyError(err error) results.ErrorClass
+
 	// Close the connection to the blockchain node
 	Close()
 }
@@ -0,0 +1,53 @@
+package clientinterfaces
+
+import (
+	"diablo-benchmark/core/results"
+	"strings"
+)
+
+// errorPattern maps a substring of an error message to the error class
+type errorPattern struct {
+	substring string             // Substring of the (lowercase) error message
+	class     results.ErrorClass // Class of the errors containing the substring
+}
+
+// commonErrorPatterns are the errors shared by all blockchains (network errors)
+var commonErrorPatterns = []errorPattern{
+	{"connection refused", results.ErrorConnectionRefused},
+	{"deadline exceeded", results.ErrorTimeout},
+	{"timeout", results.ErrorTimeout},
+	{"timed out", results.ErrorTimeout},
+}
+
+// ethereumErrorPatterns are the errors returned by Ethereum nodes
+var ethereumErrorPatterns = []errorPattern{
+	{"nonce too low", results.ErrorNonceTooLow},
+	{"underpriced", results.ErrorUnderpriced},
+	{"revert", results.ErrorRevert},
+}
+
+// fabricErrorPatterns are the errors returned by the Fabric gateway
+var fabricErrorPatterns = []errorPattern{
+	{"endorsement_policy_failure", results.ErrorEndorsement},
+	{"endorsement failure", results.ErrorEndorsement},
+	{"failed to endorse", results.ErrorEndorsement},
+	{"mvcc_read_conflict", results.ErrorMVCCConflict},
+	{"phantom_read_conflict", results.ErrorMVCCConflict},
+}
+
+// classifyError returns the class of the first pattern matching the error,
+// checking the blockchain patterns before the common ones.
+func classifyError(err error, patterns []errorPattern) results.ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, p := range append(patterns, commonErrorPatterns...) {
+		if strings.Contains(msg, p.substring) {
+			return p.class
+		}
+	}
+
+	return results.ErrorOther
+}
@@ -0,0 +1,140 @@
+package clientinterfaces
+
+import (
+	"diablo-benchmark/core/configs"
+	"diablo-benchmark/core/results"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestClassifyError(t *testing.T) {
+	e := &EthereumInterface{}
+	f := &FabricInterface{}
+
+	cases := []struct {
+		client   BlockchainInterface
+		err      error
+		expected results.ErrorClass
+	}{
+		{e, errors.New("nonce too low"), results.ErrorNonceTooLow},
+		{e, errors.New("replacement transaction underpriced"), results.ErrorUnderpriced},
+		{e, errors.New("execution reverted"), results.ErrorRevert},
+		{e, errors.New("dial tcp 127.0.0.1:8545: connect: connection refused"), results.ErrorConnectionRefused},
+		{e, errors.New("context deadline exceeded"), results.ErrorTimeout},
+		{f, errors.New("transaction invalidated with status (MVCC_READ_CONFLICT)"), results.ErrorMVCCConflict},
+		{f, errors.New("Failed to submit: ENDORSEMENT_POLICY_FAILURE"), results.ErrorEndorsement},
+		{f, errors.New("something unexpected"), results.ErrorOther},
+	}
+
+	for _, c := range cases {
+		if class := c.client.ClassifyError(c.err); class != c.expected {
+			t.Errorf("%q: expected %s, got %s", c.err, c.expected, class)
+		}
+	}
+}
+
+func TestEthereumUncommittedErrors(t *testing.T) {
+	e := &EthereumInterface{}
+	e.Init(&configs.ChainConfig{})
+	e.ThroughputTicker = time.NewTicker(time.Hour)
+	e.Throughputs = []float64{0}
+
+	sent := time.Now()
+	e.SentOrder = []string{"committed", "failed", "uncommitted"}
+	e.SentFunctions = []string{"", "", ""}
+	e.TransactionInfo["committed"] = []time.Time{sent, sent.Add(time.Second)}
+	e.TransactionInfo["failed"] = []time.Time{sent}
+	e.TransactionInfo["uncommitted"] = []time.Time{sent}
+	e.TransactionErrors["failed"] = results.ErrorNonceTooLow
+	e.Fail = 1
+
+	res := e.Cleanup()
+
+	expected := []results.ErrorClass{"", results.ErrorNonceTooLow, results.ErrorTimeout}
+	for i, tx := range res.Transactions {
+		if tx.Error != expected[i] {
+			t.Errorf("%s: expected error %q, got %q", tx.ID, expected[i], tx.Error)
+		}
+	}
+	if res.Success != 1 || res.Fail != 2 {
+		t.Errorf("expected 1 success and 2 failures, got %d and %d", res.Success, res.Fail)
+	}
+}
+
+func TestEthereumCompactCleanup(t *testing.T) {
+	e := &EthereumInterface{}
+	e.Init(&configs.ChainConfig{})
+	e.SetCompact(true)
+	e.ThroughputTicker = time.NewTicker(time.Hour)
+	e.Throughputs = []float64{0}
+
+	sent := time.Now()
+	e.notifyCommit(sent, sent.Add(time.Second))
+	e.notifyFailure(results.ErrorNonceTooLow)
+	e.TransactionInfo["uncommitted"] = []time.Time{sent}
+
+	res := e.Cleanup()
+
+	if len(res.Transactions) != 0 || len(res.TxLatencies) != 0 {
+		t.Errorf("expected no transaction state, got %d records and %d latencies", len(res.Transactions), len(res.TxLatencies))
+	}
+	if res.Histogram.Count != 1 || res.AverageLatency != 1000 {
+		t.Errorf("expected 1 latency of 1000ms, got %d with an average of %.3f", res.Histogram.Count, res.AverageLatency)
+	}
+	if res.Success != 1 || res.Fail != 2 {
+		t.Errorf("expected 1 success and 2 failures, got %d and %d", res.Success, res.Fail)
+	}
+	if res.Errors[results.ErrorNonceTooLow] != 1 || res.Errors[results.ErrorTimeout] != 1 {
+		t.Errorf("expected a nonce and a timeout error, got %v", res.Errors)
+	}
+}
+
+// receiptService serves the receipts of the transactions with the given status
+type receiptService struct {
+	statuses map[common.Hash]uint64
+}
+
+func (s *receiptService) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	status, ok := s.statuses[hash]
+	if !ok {
+		return nil, nil
+	}
+
+	return map[string]interface{}{
+		"status":            hexutil.Uint64(status),
+		"cumulativeGasUsed": hexutil.Uint64(21000),
+		"gasUsed":           hexutil.Uint64(21000),
+		"logsBloom":         ethtypes.Bloom{},
+		"logs":              []*ethtypes.Log{},
+		"transactionHash":   hash,
+	}, nil
+}
+
+func TestEthereumReverted(t *testing.T) {
+	committed := common.HexToHash("0x01")
+	reverted := common.HexToHash("0x02")
+	missing := common.HexToHash("0x03")
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	service := &receiptService{statuses: map[common.Hash]uint64{
+		committed: ethtypes.ReceiptStatusSuccessful,
+		reverted:  ethtypes.ReceiptStatusFailed,
+	}}
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &EthereumInterface{primaryRPC: rpc.DialInProc(server)}
+	res := e.reverted([]common.Hash{committed, reverted, missing})
+
+	if len(res) != 1 || !res[reverted] {
+		t.Errorf("expected only %s to be reverted, got %v", reverted.String(), res)
+	}
+}
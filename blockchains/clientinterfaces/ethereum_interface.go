@@ -5,31 +5,50 @@ package clientinterfaces
 
 import (
 	"context"
+	"diablo-benchmark/blockchains/types"
 	"diablo-benchmark/blockchains/workloadgenerators"
 	"diablo-benchmark/core/configs"
 	"diablo-benchmark/core/results"
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
 )
 
+// receiptTimeout is the timeout to fetch the receipts of the committed transactions of a block
+const receiptTimeout = 5 * time.Second
+
 // EthereumInterface is the the Ethereum implementation of the clientinterface
 // Provides functionality to interaact with the Ethereum blockchain
 type EthereumInterface struct {
-	PrimaryNode      *ethclient.Client      // The primary node connected for this client.
-	SecondaryNodes   []*ethclient.Client    // The other node information (for secure reads etc.)
-	SubscribeDone    chan bool              // Event channel that will unsub from events
-	TransactionInfo  map[string][]time.Time // Transaction information
-	HandlersStarted  bool                   // Have the handlers been initiated?
-	StartTime        time.Time              // Start time of the benchmark
-	ThroughputTicker *time.Ticker           // Ticker for throughput (1s)
-	Throughputs      []float64              // Throughput over time with 1 second intervals
+	PrimaryNode       *ethclient.Client                                 // The primary node connected for this client.
+	primaryRPC        *rpc.Client                                       // RPC client of the primary node, to batch the receipt requests
+	SecondaryNodes    []*ethclient.Client                               // The other node information (for secure reads etc.)
+	SubscribeDone     chan bool                                         // Event channel that will unsub from events
+	TransactionInfo   map[string][]time.Time                            // Transaction information
+	infoLock          sync.Mutex                                        // Lock on the transaction information, written by the sending and block routines
+	SentOrder         []string                                          // Hash of each transaction in the order they were sent
+	SentFunctions     []string                                          // Selector of the function called by each transaction in the order they were sent
+	TransactionErrors map[string]results.ErrorClass                     // Class of the error of each failed transaction
+	errorsLock        sync.Mutex                                        // Lock on the transaction errors, written by the sending routines
+	TransactionStages map[string]*types.TransactionBenchmarkInformation // Time of the stages of each transaction
+	pendingFinality   map[uint64][]string                               // Hash of the transactions of each block below the confirmation depth
+	confirmations     uint64                                            // Blocks on top of the block of a transaction for it to be final
+	stagesLock        sync.Mutex                                        // Lock on the stages, written by the sending and block routines
+	HandlersStarted   bool                                              // Have the handlers been initiated?
+	node              string                                            // Address of the primary node
+	sendPool          *sendPool                                         // Routines sending the transactions
+	StartTime         time.Time                                         // Start time of the benchmark
+	ThroughputTicker  *time.Ticker                                      // Ticker for throughput (1s)
+	Throughputs       []float64                                         // Throughput over time with 1 second intervals
 	GenericInterface
 }
 
@@ -37,9 +56,16 @@ type EthereumInterface struct {
 func (e *EthereumInterface) Init(chainConfig *configs.ChainConfig) {
 	e.Nodes = chainConfig.Nodes
 	e.TransactionInfo = make(map[string][]time.Time, 0)
+	e.SentOrder = make([]string, 0)
+	e.SentFunctions = make([]string, 0)
+	e.TransactionErrors = make(map[string]results.ErrorClass)
+	e.TransactionStages = make(map[string]*types.TransactionBenchmarkInformation)
+	e.pendingFinality = make(map[uint64][]string)
+	e.confirmations = uint64(chainConfig.Confirmations)
 	e.SubscribeDone = make(chan bool)
 	e.HandlersStarted = false
 	e.NumTxDone = 0
+	e.sendPool = newSendPool(chainConfig.SendConcurrency)
 }
 
 // Cleanup formats results and unsubscribes from the blockchain
@@ -57,9 +83,12 @@ func (e *EthereumInterface) Cleanup() results.Results {
 
 	var endTime time.Time
 
+	// Every transaction that did not commit failed, whether or not it returned an error
 	success := uint(0)
-	fails := uint(e.Fail)
+	fails := uint(0)
 
+	e.infoLock.Lock()
+	defer e.infoLock.Unlock()
 	for _, v := range e.TransactionInfo {
 		if len(v) > 1 {
 			txLatency := v[1].Sub(v[0]).Milliseconds()
@@ -79,6 +108,29 @@ func (e *EthereumInterface) Cleanup() results.Results {
 		zap.Uint("success", success),
 		zap.Uint("fail", fails))
 
+	// Commit time and stages of each transaction in the order they were sent
+	e.stagesLock.Lock()
+	defer e.stagesLock.Unlock()
+	txRecords := make([]results.TransactionRecord, len(e.SentOrder))
+	for i, hash := range e.SentOrder {
+		txRecords[i].ID = hash
+		txRecords[i].Function = e.SentFunctions[i]
+		if v := e.TransactionInfo[hash]; len(v) > 1 {
+			txRecords[i].Committed = v[1].UnixNano()
+		}
+		txRecords[i].Error = e.TransactionErrors[hash]
+		// Transactions still uncommitted at the end of the benchmark timed out
+		if txRecords[i].Committed == 0 && txRecords[i].Error == "" {
+			txRecords[i].Error = results.ErrorTimeout
+		}
+		if info, ok := e.TransactionStages[hash]; ok {
+			txRecords[i].Acknowledged = int64(info.RequestResponse)
+			txRecords[i].Included = int64(info.BlockTime)
+			txRecords[i].Finalized = int64(info.FinalTime)
+			txRecords[i].Block = info.BlockNumber
+		}
+	}
+
 	// Calculate the throughput and latencies
 	var throughput float64
 	if len(txLatencies) > 0 {
@@ -105,14 +157,29 @@ func (e *EthereumInterface) Cleanup() results.Results {
 		zap.String("ThroughputWindow", fmt.Sprintf("%v", calculatedThroughputSeconds)),
 	)
 
-	return results.Results{
+	res := results.Results{
 		TxLatencies:       txLatencies,
 		AverageLatency:    avgLatency,
 		Throughput:        averageThroughput,
 		ThroughputSeconds: calculatedThroughputSeconds,
 		Success:           success,
 		Fail:              fails,
+		Transactions:      txRecords,
+		Node:              e.node,
+	}
+
+	// In compact mode only the uncommitted transactions are left, they timed out
+	if e.compact {
+		res.Histogram, res.Errors = e.compactResults(fails)
+		res.AverageLatency = res.Histogram.Mean()
+		res.Success = uint(res.Histogram.Count)
+		res.Fail = 0
+		for _, count := range res.Errors {
+			res.Fail += count
+		}
 	}
+
+	return res
 }
 
 // throughputSeconds calculates the throughput over time, to show dynamic
@@ -169,16 +236,162 @@ func (e *EthereumInterface) parseBlocksForTransactions(blockNumber *big.Int) {
 	}
 
 	tNow := time.Now()
+
+	// Only contract calls can revert, which only their receipt tells. The
+	// receipts of the block are fetched in a single batch.
+	var calls []common.Hash
+	for _, v := range block.Transactions() {
+		e.infoLock.Lock()
+		_, ok := e.TransactionInfo[v.Hash().String()]
+		e.infoLock.Unlock()
+		if ok && len(v.Data()) > 0 {
+			calls = append(calls, v.Hash())
+		}
+	}
+	reverted := e.reverted(calls)
+
 	var tAdd uint64
+	var included []string
 	for _, v := range block.Transactions() {
 		tHash := v.Hash().String()
-		if _, ok := e.TransactionInfo[tHash]; ok {
-			e.TransactionInfo[tHash] = append(e.TransactionInfo[tHash], tNow)
+		e.infoLock.Lock()
+		times, ok := e.TransactionInfo[tHash]
+		e.infoLock.Unlock()
+		if ok {
+			included = append(included, tHash)
+
+			if reverted[v.Hash()] {
+				e.recordError(tHash, results.ErrorRevert)
+				atomic.AddUint64(&e.Fail, 1)
+				atomic.AddUint64(&e.NumTxDone, 1)
+				e.notifyFailure(results.ErrorRevert)
+				e.notifyCompletion(false)
+				continue
+			}
+
+			// The latency of the transaction is all that is kept in compact mode
+			e.infoLock.Lock()
+			if e.compact {
+				delete(e.TransactionInfo, tHash)
+			} else {
+				e.TransactionInfo[tHash] = append(e.TransactionInfo[tHash], tNow)
+			}
+			e.infoLock.Unlock()
+			e.notifyCommit(times[0], tNow)
 			tAdd++
 		}
 	}
 
+	if !e.compact {
+		e.recordInclusion(block.NumberU64(), block.Time(), tNow, included)
+	}
+
 	atomic.AddUint64(&e.NumTxDone, tAdd)
+
+	for i := uint64(0); i < tAdd; i++ {
+		e.notifyCompletion(true)
+	}
+}
+
+// recordError records the class of the error of a failed transaction, which
+// is only counted in compact mode
+func (e *EthereumInterface) recordError(hash string, class results.ErrorClass) {
+	if e.compact {
+		e.infoLock.Lock()
+		delete(e.TransactionInfo, hash)
+		e.infoLock.Unlock()
+		return
+	}
+
+	e.errorsLock.Lock()
+	e.TransactionErrors[hash] = class
+	e.errorsLock.Unlock()
+}
+
+// reverted returns the transactions whose receipt has a failed status, fetching
+// the receipts in a single batch request. A receipt that cannot be fetched is
+// not considered reverted.
+func (e *EthereumInterface) reverted(hashes []common.Hash) map[common.Hash]bool {
+	reverted := make(map[common.Hash]bool)
+	if len(hashes) == 0 || e.primaryRPC == nil {
+		return reverted
+	}
+
+	receipts := make([]*ethtypes.Receipt, len(hashes))
+	batch := make([]rpc.BatchElem, len(hashes))
+	for i, hash := range hashes {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), receiptTimeout)
+	defer cancel()
+
+	if err := e.primaryRPC.BatchCallContext(ctx, batch); err != nil {
+		zap.L().Debug("failed to get the receipts of the transactions",
+			zap.Int("transactions", len(hashes)),
+			zap.Error(err))
+		return reverted
+	}
+
+	for i, elem := range batch {
+		if elem.Error != nil || receipts[i] == nil {
+			zap.L().Debug("failed to get the receipt of the transaction",
+				zap.String("hash", hashes[i].String()),
+				zap.Error(elem.Error))
+			continue
+		}
+
+		if receipts[i].Status == ethtypes.ReceiptStatusFailed {
+			reverted[hashes[i]] = true
+		}
+	}
+
+	return reverted
+}
+
+// stage returns the stages of the transaction, must be called with the stages lock held
+func (e *EthereumInterface) stage(hash string) *types.TransactionBenchmarkInformation {
+	info, ok := e.TransactionStages[hash]
+	if !ok {
+		info = &types.TransactionBenchmarkInformation{Hash: hash}
+		e.TransactionStages[hash] = info
+	}
+
+	return info
+}
+
+// recordInclusion records the block that included the given transactions, its
+// timestamp (seconds) and the time it was observed. The transactions of the
+// blocks that now have enough blocks on top of them are final.
+func (e *EthereumInterface) recordInclusion(number uint64, timestamp uint64, observed time.Time, hashes []string) {
+	e.stagesLock.Lock()
+	defer e.stagesLock.Unlock()
+
+	for _, hash := range hashes {
+		info := e.stage(hash)
+		info.BlockNumber = number
+		info.BlockTime = timestamp * uint64(time.Second)
+		info.ObservedTime = uint64(observed.UnixNano())
+	}
+
+	if len(hashes) > 0 {
+		e.pendingFinality[number] = append(e.pendingFinality[number], hashes...)
+	}
+
+	for n, pending := range e.pendingFinality {
+		if n+e.confirmations > number {
+			continue
+		}
+
+		for _, hash := range pending {
+			e.TransactionStages[hash].FinalTime = uint64(observed.UnixNano())
+		}
+		delete(e.pendingFinality, n)
+	}
 }
 
 // EventHandler subscribes to the blocks and handles the incoming information about the transactions
@@ -217,11 +430,13 @@ func (e *EthereumInterface) ParseBlocksForTransactions(startNumber uint64, endNu
 			return err
 		}
 
+		e.infoLock.Lock()
 		for _, v := range b.TransactionHashes {
 			if _, ok := e.TransactionInfo[v]; ok {
 				e.TransactionInfo[v] = append(e.TransactionInfo[v], time.Unix(int64(b.Timestamp), 0))
 			}
 		}
+		e.infoLock.Unlock()
 	}
 
 	return nil
@@ -236,14 +451,16 @@ func (e *EthereumInterface) ConnectOne(id int) error {
 	}
 
 	// Connect to the node
-	c, err := ethclient.Dial(fmt.Sprintf("ws://%s", e.Nodes[id]))
+	rpcClient, err := rpc.Dial(fmt.Sprintf("ws://%s", e.Nodes[id]))
 
 	// If there's an error, raise it.
 	if err != nil {
 		return err
 	}
 
-	e.PrimaryNode = c
+	e.primaryRPC = rpcClient
+	e.PrimaryNode = ethclient.NewClient(rpcClient)
+	e.node = e.Nodes[id]
 
 	if !e.HandlersStarted {
 		go e.EventHandler()
@@ -307,7 +524,27 @@ func (e *EthereumInterface) DeploySmartContract(tx interface{}) (interface{}, er
 func (e *EthereumInterface) _sendTx(txSigned ethtypes.Transaction) {
 	// timoutCTX, _ := context.WithTimeout(context.Background(), 5*time.Second)
 
+	hash := txSigned.Hash().String()
+	sent := time.Now()
+
+	// Record the transaction before sending it, its block may be observed
+	// before SendTransaction returns
+	e.infoLock.Lock()
+	e.TransactionInfo[hash] = []time.Time{sent}
+	e.infoLock.Unlock()
+
 	err := e.PrimaryNode.SendTransaction(context.Background(), &txSigned)
+	acknowledged := time.Now()
+
+	if !e.compact {
+		e.stagesLock.Lock()
+		info := e.stage(hash)
+		info.SentTime = uint64(sent.UnixNano())
+		if err == nil {
+			info.RequestResponse = uint64(acknowledged.UnixNano())
+		}
+		e.stagesLock.Unlock()
+	}
 
 	// The transaction failed - this could be if it was reproposed, or, just failed.
 	// We need to make sure that if it was re-proposed it doesn't count as a "success" on this node.
@@ -315,11 +552,14 @@ func (e *EthereumInterface) _sendTx(txSigned ethtypes.Transaction) {
 		zap.L().Debug("Err",
 			zap.Error(err),
 		)
+		class := e.ClassifyError(err)
+		e.recordError(hash, class)
 		atomic.AddUint64(&e.Fail, 1)
 		atomic.AddUint64(&e.NumTxDone, 1)
+		e.notifyFailure(class)
+		e.notifyCompletion(false)
 	}
 
-	e.TransactionInfo[txSigned.Hash().String()] = []time.Time{time.Now()}
 	atomic.AddUint64(&e.NumTxSent, 1)
 }
 
@@ -329,11 +569,30 @@ func (e *EthereumInterface) _sendTx(txSigned ethtypes.Transaction) {
 func (e *EthereumInterface) SendRawTransaction(tx interface{}) error {
 	// NOTE: type conversion might be slow, there might be a better way to send this.
 	txSigned := tx.(*ethtypes.Transaction)
-	go e._sendTx(*txSigned)
+	if !e.compact {
+		e.SentOrder = append(e.SentOrder, txSigned.Hash().String())
+		e.SentFunctions = append(e.SentFunctions, functionSelector(txSigned.Data()))
+	}
+	e.sendPool.submit(func() { e._sendTx(*txSigned) })
 
 	return nil
 }
 
+// functionSelector returns the selector of the function called by the
+// transaction data (the first 4 bytes), empty for transfers and deployments.
+func functionSelector(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+
+	return fmt.Sprintf("0x%x", data[:4])
+}
+
+// ClassifyError returns the class of the error returned by the Ethereum node
+func (e *EthereumInterface) ClassifyError(err error) results.ErrorClass {
+	return classifyError(err, ethereumErrorPatterns)
+}
+
 // SecureRead will implement a "secure read" - will read a value from all connected nodes to ensure that the
 // value is the same.
 func (e *EthereumInterface) SecureRead(callFunc string, callPrams []byte) (interface{}, error) {
@@ -369,6 +628,8 @@ func (e *EthereumInterface) GetBlockByNumber(index uint64) (block GenericBlock,
 		Timestamp:         b.Time(),
 		TransactionNumber: b.Transactions().Len(),
 		TransactionHashes: txList,
+		GasUsed:           b.GasUsed(),
+		GasLimit:          b.GasLimit(),
 	}, nil
 }
 
@@ -387,6 +648,9 @@ func (e *EthereumInterface) GetBlockHeight() (uint64, error) {
 
 // Close all the client connections
 func (e *EthereumInterface) Close() {
+	// Let the pending sends finish
+	e.sendPool.close()
+
 	// Close the main client connection
 	e.PrimaryNode.Close()
 
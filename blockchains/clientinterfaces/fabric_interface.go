@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -26,11 +28,21 @@ type FabricInterface struct {
 	Contract      *gateway.Contract             // The smart contract we will be interacting with (only supporting one contract workload for now)
 	ccpPath       string                        // connection-profile path to configure the gateway
 	commitChannel chan *types.FabricCommitEvent // channel where we continuously listen to commit events to register throughput
-
-	TransactionInfo  map[uint64][]time.Time // Transaction information (used for throughput calculation)
-	StartTime        time.Time              // Start time of the benchmark
-	ThroughputTicker *time.Ticker           // Ticker for throughput (1s)
-	Throughputs      []float64              // Throughput over time with 1 second intervals
+	sendPool      *sendPool                     // routines submitting the transactions to the gateway
+
+	TransactionInfo   map[uint64][]time.Time        // Transaction information (used for throughput calculation)
+	SentOrder         []uint64                      // ID of each transaction in the order they were sent
+	SentFunctions     []string                      // Function called by each transaction in the order they were sent
+	SentTypes         []string                      // Type of the function ("read" or "write") called by each transaction in the order they were sent
+	infoLock          sync.Mutex                    // Lock on the transaction information and the sent transactions, written by the sending and commit routines
+	TransactionErrors map[uint64]results.ErrorClass // Class of the error of each failed transaction
+	errorsLock        sync.Mutex                    // Lock on the transaction errors, written by the commit routine
+	TransactionBlocks map[uint64]uint64             // Block each write transaction was committed in
+	TransactionAcks   map[uint64]time.Time          // Time the gateway returned each transaction
+	stagesLock        sync.Mutex                    // Lock on the transaction blocks and acknowledgements, written by the commit routine
+	StartTime         time.Time                     // Start time of the benchmark
+	ThroughputTicker  *time.Ticker                  // Ticker for throughput (1s)
+	Throughputs       []float64                     // Throughput over time with 1 second intervals
 	GenericInterface
 }
 
@@ -45,7 +57,17 @@ func (f *FabricInterface) Init(chainConfig *configs.ChainConfig) {
 		Key:   mapConfig["key"].(string),
 	}
 	f.NumTxDone = 0
+	f.sendPool = newSendPool(chainConfig.SendConcurrency)
+	// Created here rather than when parsing, as the workload can be parsed one
+	// interval at a time. The listener drains it, the buffer absorbs bursts.
+	f.commitChannel = make(chan *types.FabricCommitEvent, chainConfig.SendConcurrency)
 	f.TransactionInfo = make(map[uint64][]time.Time, 0)
+	f.SentOrder = make([]uint64, 0)
+	f.SentFunctions = make([]string, 0)
+	f.SentTypes = make([]string, 0)
+	f.TransactionErrors = make(map[uint64]results.ErrorClass)
+	f.TransactionBlocks = make(map[uint64]uint64)
+	f.TransactionAcks = make(map[uint64]time.Time)
 
 	err := os.Setenv("DISCOVERY_AS_LOCALHOST", mapConfig["localHost"].(string))
 	if err != nil {
@@ -106,6 +128,14 @@ func (f *FabricInterface) Cleanup() results.Results {
 
 	var endTime time.Time
 
+	// The commits may still be delivered while the results are formatted
+	f.infoLock.Lock()
+	defer f.infoLock.Unlock()
+	f.errorsLock.Lock()
+	defer f.errorsLock.Unlock()
+	f.stagesLock.Lock()
+	defer f.stagesLock.Unlock()
+
 	for _, v := range f.TransactionInfo {
 		if len(v) > 1 {
 			txLatency := v[1].Sub(v[0]).Milliseconds()
@@ -120,6 +150,38 @@ func (f *FabricInterface) Cleanup() results.Results {
 	success := uint(f.Success)
 	fails := uint(f.Fail)
 
+	// Commit time of each transaction in the order they were sent
+	timeouts := uint(0)
+	txRecords := make([]results.TransactionRecord, len(f.SentOrder))
+	for i, ID := range f.SentOrder {
+		txRecords[i].ID = strconv.FormatUint(ID, 10)
+		txRecords[i].Function = f.SentFunctions[i]
+		txRecords[i].Type = f.SentTypes[i]
+		if v := f.TransactionInfo[ID]; len(v) > 1 {
+			txRecords[i].Committed = v[1].UnixNano()
+		}
+		if ack, ok := f.TransactionAcks[ID]; ok {
+			txRecords[i].Acknowledged = ack.UnixNano()
+		}
+		// Fabric blocks carry no timestamp, and are final once committed
+		if block, ok := f.TransactionBlocks[ID]; ok {
+			txRecords[i].Block = block
+			txRecords[i].Finalized = txRecords[i].Committed
+		}
+		txRecords[i].Error = f.TransactionErrors[ID]
+		// Transactions still uncommitted at the end of the benchmark timed out
+		if txRecords[i].Committed == 0 && txRecords[i].Error == "" {
+			txRecords[i].Error = results.ErrorTimeout
+			timeouts++
+		}
+	}
+
+	// In compact mode only the uncommitted transactions are left
+	if f.compact {
+		timeouts = uint(len(f.TransactionInfo))
+	}
+	fails += timeouts
+
 	zap.L().Debug("Statistics being returned",
 		zap.Uint("success", success),
 		zap.Uint("fail", fails))
@@ -150,14 +212,22 @@ func (f *FabricInterface) Cleanup() results.Results {
 		zap.String("ThroughputWindow", fmt.Sprintf("%v", calculatedThroughputSeconds)),
 	)
 
-	return results.Results{
+	res := results.Results{
 		TxLatencies:       txLatencies,
 		AverageLatency:    avgLatency,
 		Throughput:        averageThroughput,
 		ThroughputSeconds: calculatedThroughputSeconds,
 		Success:           success,
 		Fail:              fails,
+		Transactions:      txRecords,
+	}
+
+	if f.compact {
+		res.Histogram, res.Errors = f.compactResults(timeouts)
+		res.AverageLatency = res.Histogram.Mean()
 	}
+
+	return res
 }
 
 // throughputSeconds calculates the throughput over time, to show dynamic
@@ -183,24 +253,68 @@ func (f *FabricInterface) throughputSeconds() {
 func (f *FabricInterface) listenForCommits() {
 	for {
 		select {
-		case commit := <-f.commitChannel:
-
-			ID := commit.ID
-			zap.L().Debug("CommitChannel",
-				zap.Uint64("ID", ID))
-			// transaction failed, incrementing number of done and failed transactions
-			if !commit.Valid {
-				atomic.AddUint64(&f.Fail, 1)
-			} else {
-				//transaction validated, making the note of the time of return
-				f.TransactionInfo[ID] = append(f.TransactionInfo[ID], commit.CommitTime)
-				atomic.AddUint64(&f.Success, 1)
+		case commit, ok := <-f.commitChannel:
+			// The channel is closed when the interface is closed
+			if !ok {
+				return
 			}
 
-			atomic.AddUint64(&f.NumTxDone, 1)
+			f.handleCommit(commit)
+		}
+	}
+
+}
+
+// handleCommit records the outcome of a transaction returned by the gateway
+func (f *FabricInterface) handleCommit(commit *types.FabricCommitEvent) {
+	ID := commit.ID
+	zap.L().Debug("CommitChannel",
+		zap.Uint64("ID", ID))
+	// transaction failed, incrementing number of done and failed transactions
+	if !commit.Acknowledged.IsZero() && !f.compact {
+		f.stagesLock.Lock()
+		f.TransactionAcks[ID] = commit.Acknowledged
+		f.stagesLock.Unlock()
+	}
+
+	if !commit.Valid {
+		class := f.ClassifyError(commit.Err)
+		if f.compact {
+			f.infoLock.Lock()
+			delete(f.TransactionInfo, ID)
+			f.infoLock.Unlock()
+		} else {
+			f.errorsLock.Lock()
+			f.TransactionErrors[ID] = class
+			f.errorsLock.Unlock()
+		}
+		atomic.AddUint64(&f.Fail, 1)
+		f.notifyFailure(class)
+	} else {
+		//transaction validated, making the note of the time of return
+		f.infoLock.Lock()
+		times := append(f.TransactionInfo[ID], commit.CommitTime)
+		// The latency of the transaction is all that is kept in compact mode
+		if f.compact {
+			delete(f.TransactionInfo, ID)
+		} else {
+			f.TransactionInfo[ID] = times
+		}
+		f.infoLock.Unlock()
+
+		if commit.Block > 0 && !f.compact {
+			f.stagesLock.Lock()
+			f.TransactionBlocks[ID] = commit.Block
+			f.stagesLock.Unlock()
+		}
+		atomic.AddUint64(&f.Success, 1)
+		if len(times) > 1 {
+			f.notifyCommit(times[0], commit.CommitTime)
 		}
 	}
 
+	atomic.AddUint64(&f.NumTxDone, 1)
+	f.notifyCompletion(commit.Valid)
 }
 
 // Start handles the starting aspects of the benchmark
@@ -235,8 +349,6 @@ func (f *FabricInterface) ParseWorkload(workload workloadgenerators.WorkerThread
 	}
 
 	f.TotalTx = len(parsedWorkload)
-	// the commitChannel buffer length should be the total number of transactions so that it's not a blocker
-	f.commitChannel = make(chan *types.FabricCommitEvent, f.TotalTx)
 
 	return parsedWorkload, nil
 }
@@ -262,6 +374,20 @@ func (f *FabricInterface) DeploySmartContract(tx interface{}) (interface{}, erro
 	return nil, nil
 }
 
+// recordSent makes note of the time the transaction is sent
+func (f *FabricInterface) recordSent(transaction *types.FabricTX) {
+	f.infoLock.Lock()
+	f.TransactionInfo[transaction.ID] = []time.Time{time.Now()}
+	if !f.compact {
+		f.SentOrder = append(f.SentOrder, transaction.ID)
+		f.SentFunctions = append(f.SentFunctions, transaction.FunctionName)
+		f.SentTypes = append(f.SentTypes, transaction.FunctionType)
+	}
+	f.infoLock.Unlock()
+
+	atomic.AddUint64(&f.NumTxSent, 1)
+}
+
 // SendRawTransaction sends the transaction by the gateway
 func (f *FabricInterface) SendRawTransaction(tx interface{}) error {
 	transaction := tx.(*types.FabricTX)
@@ -269,9 +395,7 @@ func (f *FabricInterface) SendRawTransaction(tx interface{}) error {
 	zap.L().Debug("Submitting TX",
 		zap.Uint64("ID", transaction.ID))
 
-	// making note of the time we send the transaction
-	f.TransactionInfo[transaction.ID] = []time.Time{time.Now()}
-	atomic.AddUint64(&f.NumTxSent, 1)
+	f.recordSent(transaction)
 
 	if transaction.FunctionType == "write" {
 		//submitTransaction does everything under the hood for us.
@@ -282,8 +406,23 @@ func (f *FabricInterface) SendRawTransaction(tx interface{}) error {
 		//a single transaction, which it then submits to the orderer. The orderer collects and sequences transactions from various application clients into a block of transactions.
 		//These blocks are distributed to every peer in the network, where every transaction is validated and committed.
 		//Finally, the SDK is notified via an event, allowing it to return control to the application.
-		go func() {
-			_, err := f.Contract.SubmitTransaction(transaction.FunctionName, transaction.Args...)
+		//The commit event of the transaction tells the block it was committed in.
+		f.sendPool.submit(func() {
+			var block uint64
+			var acknowledged time.Time
+			txn, err := f.Contract.CreateTransaction(transaction.FunctionName)
+			if err == nil {
+				events := txn.RegisterCommitEvent()
+				_, err = txn.Submit(transaction.Args...)
+				acknowledged = time.Now()
+				select {
+				case event, ok := <-events:
+					if ok {
+						block = event.BlockNumber
+					}
+				default:
+				}
+			}
 			time := time.Now()
 
 			if err != nil {
@@ -292,32 +431,42 @@ func (f *FabricInterface) SendRawTransaction(tx interface{}) error {
 			}
 			valid := err == nil
 			commit := types.FabricCommitEvent{
-				Valid:      valid,
-				ID:         transaction.ID,
-				CommitTime: time,
+				Valid:        valid,
+				ID:           transaction.ID,
+				CommitTime:   time,
+				Acknowledged: acknowledged,
+				Block:        block,
+				Err:          err,
 			}
 			f.commitChannel <- &commit
-		}()
+		})
 
 	} else {
 		//EvaluteTransaction is much less expensive and only queries one peer for its world state
-		go func() {
+		f.sendPool.submit(func() {
 			_, err := f.Contract.EvaluateTransaction(transaction.FunctionName, transaction.Args...)
 			time := time.Now()
 			valid := err == nil
 			commit := types.FabricCommitEvent{
-				Valid:      valid,
-				ID:         transaction.ID,
-				CommitTime: time,
+				Valid:        valid,
+				ID:           transaction.ID,
+				CommitTime:   time,
+				Acknowledged: time,
+				Err:          err,
 			}
 			f.commitChannel <- &commit
-		}()
+		})
 	}
 
 	return nil
 
 }
 
+// ClassifyError returns the class of the error returned by the Fabric gateway
+func (f *FabricInterface) ClassifyError(err error) results.ErrorClass {
+	return classifyError(err, fabricErrorPatterns)
+}
+
 // SecureRead reads the value from the chain
 // (NOT NEEDED IN FABRIC) SecureRead is useful in permissionless blockchains where transaction
 // validation is not always clear but transactions are always clearly rejected or commited in Hyperledger Fabric
@@ -350,6 +499,8 @@ func (f *FabricInterface) ParseBlocksForTransactions(startNumber uint64, endNumb
 
 // Close the connection to the blockchain node
 func (f *FabricInterface) Close() {
+	// Let the pending submissions finish before closing the commit channel
+	f.sendPool.close()
 	f.Gateway.Close()
 	close(f.commitChannel)
 }
@@ -0,0 +1,109 @@
+package clientinterfaces
+
+import (
+	"diablo-benchmark/blockchains/types"
+	"diablo-benchmark/core/results"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestFabric returns a Fabric interface with the transaction state of Init, without a gateway
+func newTestFabric() *FabricInterface {
+	return &FabricInterface{
+		TransactionInfo:   make(map[uint64][]time.Time),
+		TransactionErrors: make(map[uint64]results.ErrorClass),
+		TransactionBlocks: make(map[uint64]uint64),
+		TransactionAcks:   make(map[uint64]time.Time),
+		ThroughputTicker:  time.NewTicker(time.Hour),
+		Throughputs:       []float64{0},
+		commitChannel:     make(chan *types.FabricCommitEvent, 8),
+	}
+}
+
+func TestFabricConcurrentCommits(t *testing.T) {
+	const numTx = 500
+
+	for _, compact := range []bool{false, true} {
+		f := newTestFabric()
+		f.SetCompact(compact)
+
+		listening := make(chan struct{})
+		go func() {
+			f.listenForCommits()
+			close(listening)
+		}()
+
+		// Each transaction is committed by another routine once it is sent
+		sent := make(chan uint64, numTx)
+		var commits sync.WaitGroup
+		commits.Add(1)
+		go func() {
+			defer commits.Done()
+			for ID := range sent {
+				commit := &types.FabricCommitEvent{Valid: ID%5 != 0, ID: ID, CommitTime: time.Now(), Block: ID/10 + 1}
+				if !commit.Valid {
+					commit.Err = errors.New("transaction invalidated with status (MVCC_READ_CONFLICT)")
+				}
+				f.commitChannel <- commit
+			}
+		}()
+
+		for ID := uint64(0); ID < numTx; ID++ {
+			f.recordSent(&types.FabricTX{ID: ID, FunctionType: "write"})
+			sent <- ID
+			if ID == numTx/2 {
+				f.Cleanup()
+			}
+		}
+		close(sent)
+		commits.Wait()
+		close(f.commitChannel)
+		<-listening
+
+		res := f.Cleanup()
+
+		if res.Success != numTx*4/5 || res.Fail != numTx/5 {
+			t.Errorf("compact %t: expected %d successes and %d failures, got %d and %d", compact, numTx*4/5, numTx/5, res.Success, res.Fail)
+		}
+		if !compact && len(res.Transactions) != numTx {
+			t.Errorf("expected %d records, got %d", numTx, len(res.Transactions))
+		}
+	}
+}
+
+func TestFabricUncommittedErrors(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		f := newTestFabric()
+		f.SetCompact(compact)
+
+		for ID := uint64(0); ID < 3; ID++ {
+			f.recordSent(&types.FabricTX{ID: ID, FunctionType: "write"})
+		}
+		now := time.Now()
+		f.handleCommit(&types.FabricCommitEvent{Valid: true, ID: 0, CommitTime: now, Acknowledged: now})
+		f.handleCommit(&types.FabricCommitEvent{ID: 1, Err: errors.New("ENDORSEMENT_POLICY_FAILURE")})
+
+		res := f.Cleanup()
+
+		if res.Success != 1 || res.Fail != 2 {
+			t.Errorf("compact %t: expected 1 success and 2 failures, got %d and %d", compact, res.Success, res.Fail)
+		}
+		if compact {
+			if res.Errors[results.ErrorEndorsement] != 1 || res.Errors[results.ErrorTimeout] != 1 {
+				t.Errorf("expected an endorsement and a timeout error, got %v", res.Errors)
+			}
+			continue
+		}
+		if res.Transactions[0].Acknowledged != now.UnixNano() {
+			t.Errorf("expected the committed transaction to be acknowledged at %d, got %d", now.UnixNano(), res.Transactions[0].Acknowledged)
+		}
+		expected := []results.ErrorClass{"", results.ErrorEndorsement, results.ErrorTimeout}
+		for i, tx := range res.Transactions {
+			if tx.Error != expected[i] {
+				t.Errorf("%s: expected error %q, got %q", tx.ID, expected[i], tx.Error)
+			}
+		}
+	}
+}
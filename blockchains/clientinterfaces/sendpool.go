@@ -0,0 +1,46 @@
+package clientinterfaces
+
+import (
+	"diablo-benchmark/core/configs"
+	"sync"
+)
+
+// sendPool runs the sends of a connection on a bounded number of routines.
+// Submitting blocks while all routines are busy, so that a worker sending
+// faster than the connection can handle is slowed down (backpressure) rather
+// than creating a routine per transaction.
+type sendPool struct {
+	jobs chan func()    // Sends waiting for a routine
+	wg   sync.WaitGroup // All routines done
+}
+
+// newSendPool starts a pool with the given number of sending routines
+func newSendPool(size int) *sendPool {
+	if size <= 0 {
+		size = configs.DefaultSendConcurrency
+	}
+
+	p := &sendPool{jobs: make(chan func())}
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+
+	return p
+}
+
+// submit runs the send on the pool, blocking until a routine is free
+func (p *sendPool) submit(job func()) {
+	p.jobs <- job
+}
+
+// close waits for the submitted sends to finish and stops the routines
+func (p *sendPool) close() {
+	close(p.jobs)
+	p.wg.Wait()
+}
@@ -0,0 +1,39 @@
+package clientinterfaces
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendPool(t *testing.T) {
+	p := newSendPool(2)
+
+	var running, maxRunning, done int64
+	var mu sync.Mutex
+
+	for i := 0; i < 10; i++ {
+		p.submit(func() {
+			n := atomic.AddInt64(&running, 1)
+			mu.Lock()
+			if n > maxRunning {
+				maxRunning = n
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&running, -1)
+			atomic.AddInt64(&done, 1)
+		})
+	}
+
+	p.close()
+
+	if done != 10 {
+		t.Errorf("expected all 10 sends to finish on close, got %d", done)
+	}
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent sends, got %d", maxRunning)
+	}
+}
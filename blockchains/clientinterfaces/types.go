@@ -9,4 +9,6 @@ type GenericBlock struct {
 	Timestamp         uint64   // Unix timestamp of the block
 	TransactionNumber int      // Number of transactions included in the block
 	TransactionHashes []string // The hash of each transaction included in the block
+	GasUsed           uint64   // Gas used by the transactions of the block, 0 if the chain has no gas
+	GasLimit          uint64   // Gas limit of the block, 0 if the chain has no gas
 }
@@ -26,4 +26,7 @@ type FabricCommitEvent struct {
 	Valid bool
 	ID     uint64 // the ID used in client to keep track of the transaction and register throughput
 	CommitTime time.Time // the time the transaction was committed
+	Acknowledged time.Time // the time the gateway returned the transaction, zero if it was not returned
+	Block      uint64    // the block the transaction was committed in, 0 for queries
+	Err    error     // the error returned if the transaction failed
 }
@@ -2,10 +2,15 @@
 package types
 
 // TransactionBenchmarkInformation contains generic information about the
-// transaction, stores hash, sent time and time that it was mined into a block
+// transaction, stores hash, the time of each stage of the transaction from its
+// send to its finality, and the block it was mined into.
+// Times are unix nanoseconds, 0 if the stage was not reached or not measured.
 type TransactionBenchmarkInformation struct {
 	Hash            string // Unique transaction hash
 	SentTime        uint64 // Time that the transaction request was sent
-	RequestResponse uint64 // Response time that was returned
-	BlockTime       uint64 // Time that it was mined into a block.
+	RequestResponse uint64 // Response time that was returned (acknowledgement of the node)
+	BlockTime       uint64 // Time that it was mined into a block (timestamp of the block).
+	ObservedTime    uint64 // Time the client observed the block including the transaction
+	FinalTime       uint64 // Time the block including the transaction reached the confirmation depth
+	BlockNumber     uint64 // Number of the block including the transaction
 }
@@ -170,6 +170,41 @@ func (e *EthereumWorkloadGenerator) DeployContract(fromPivKey []byte, contractPa
 	}
 }
 
+// compileContract compiles the contract at the path and returns the contract
+// named in the benchmark configuration, or the first one if none is named
+func (e *EthereumWorkloadGenerator) compileContract(contractPath string) (*compiler.Contract, error) {
+	// TODO: check the 'solc' string
+	contracts, err := compiler.CompileSolidity("", contractPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("no contracts to compile")
+	}
+
+	// TODO handle case where number of contracts is greater than one
+	if e.BenchConfig.ContractInfo.Name != "" {
+		for k, v := range contracts {
+			s := strings.Split(k, ":")
+			if s[len(s)-1] == e.BenchConfig.ContractInfo.Name {
+				return v, nil
+			}
+		}
+
+		zap.L().Error(fmt.Sprintf("Failed to find contract %v in %v", e.BenchConfig.ContractInfo.Name, contracts))
+		return nil, fmt.Errorf("failed to find contract in compiled")
+	}
+
+	for k, v := range contracts {
+		zap.L().Warn("Name not provided, compiling first contract",
+			zap.String("contract", k),
+		)
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("no contracts to compile")
+}
+
 // CreateContractDeployTX creates a transaction to deploy the smart contract
 func (e *EthereumWorkloadGenerator) CreateContractDeployTX(fromPrivKey []byte, contractPath string) ([]byte, error) {
 
@@ -184,40 +219,10 @@ func (e *EthereumWorkloadGenerator) CreateContractDeployTX(fromPrivKey []byte, c
 	// Check for the existence of the contract
 	if _, err := os.Stat(contractPath); err == nil {
 		// Path exists, compile the contract and prepare the transaction
-		// TODO: check the 'solc' string
-		contracts, err := compiler.CompileSolidity("", contractPath)
+		contract, err := e.compileContract(contractPath)
 		if err != nil {
 			return []byte{}, err
 		}
-		if len(contracts) == 0 {
-			return nil, fmt.Errorf("no contracts to compile")
-		}
-
-		// TODO handle case where number of contracts is greater than one
-		var contract *compiler.Contract
-
-		if e.BenchConfig.ContractInfo.Name != "" {
-			for k, v := range contracts {
-				s := strings.Split(k, ":")
-				if s[len(s)-1] == e.BenchConfig.ContractInfo.Name {
-					contract = v
-					break
-				}
-			}
-
-			if contract == nil {
-				zap.L().Error(fmt.Sprintf("Failed to find contract %v in %v", e.BenchConfig.ContractInfo.Name, contracts))
-				return nil, fmt.Errorf("failed to find contract in compiled")
-			}
-		} else {
-			for k, v := range contracts {
-				zap.L().Warn("Name not provided, compiling first contract",
-					zap.String("contract", k),
-				)
-				contract = v
-				break
-			}
-		}
 
 		zap.L().Info("Deploying Contract",
 			zap.String("contract", e.BenchConfig.ContractInfo.Name),
@@ -828,23 +833,10 @@ func (e *EthereumWorkloadGenerator) generateContractWorkload() (Workload, error)
 					zap.L().Debug(fmt.Sprintf("tx %d for func %s", txCount, funcToCreate.Name),
 						zap.Int("secondary", secondaryID),
 						zap.Int("thread", threadID))
-					var functionParamSigs []string
-					var functionFinal string
-					if len(funcToCreate.Params) > 0 {
-
-						for _, paramVal := range funcToCreate.Params {
-							functionParamSigs = append(functionParamSigs, paramVal.Type)
-						}
-
-						functionFinal = fmt.Sprintf("%s(%s)", funcToCreate.Name, strings.Join(functionParamSigs[:], ","))
-					} else {
-						functionFinal = funcToCreate.Name
-					}
-
 					tx, txerr := e.CreateInteractionTX(
 						accFrom.PrivateKey,
 						contractAddr,
-						functionFinal,
+						functionSignature(funcToCreate),
 						funcToCreate.Params,
 						funcToCreate.PayValue,
 					)
@@ -0,0 +1,246 @@
+package workloadgenerators
+
+import (
+	"diablo-benchmark/core/configs"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"go.uber.org/zap"
+)
+
+// Keys of the chain parameters in the Ethereum workload specs
+const (
+	ethereumSpecChainID  = "chainID"
+	ethereumSpecGasPrice = "gasPrice"
+	ethereumSpecContract = "contract"
+)
+
+// functionSignature returns the signature of the contract function, e.g. storeVal(uint32)
+func functionSignature(f configs.ContractFunction) string {
+	if len(f.Params) == 0 {
+		return f.Name
+	}
+
+	var paramTypes []string
+	for _, v := range f.Params {
+		paramTypes = append(paramTypes, v.Type)
+	}
+
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(paramTypes, ","))
+}
+
+// ContractFunctions returns the functions of the contract keyed by their
+// selector in the compiled contract, which the client interface records for the
+// transactions calling them. The contract is compiled if it was not deployed by
+// this generator (e.g. when merging results). The fallback and receive
+// functions are called without a selector, as transfers.
+func (e *EthereumWorkloadGenerator) ContractFunctions() map[string]configs.ContractFunction {
+	if e.BenchConfig.TxInfo.TxType != configs.TxTypeContract {
+		return nil
+	}
+
+	if e.CompiledContract == nil {
+		contract, err := e.compileContract(e.BenchConfig.ContractInfo.Path)
+		if err != nil {
+			zap.L().Warn("failed to compile the contract, the functions of the transactions are unknown",
+				zap.Error(err))
+			return nil
+		}
+		e.CompiledContract = contract
+	}
+
+	functions := make(map[string]configs.ContractFunction)
+	for _, v := range e.BenchConfig.ContractInfo.Functions {
+		if v.Name == "fallback" || v.Name == "receive" || v.Name == "()" {
+			continue
+		}
+
+		// The transactions are created with the same lookup of the selector
+		selector, ok := e.CompiledContract.Hashes[functionSignature(v)]
+		if !ok {
+			zap.L().Warn("function not found in the compiled contract",
+				zap.String("function", functionSignature(v)))
+			continue
+		}
+		functions["0x"+strings.ToLower(selector)] = v
+	}
+
+	return functions
+}
+
+// GenerateWorkloadSpecs generates the spec of the workload of each secondary.
+// The accounts are distributed to the threads as in GenerateWorkload and the
+// nonces each thread uses are reserved without signing any transaction. The
+// contract (for contract workloads) is deployed from the primary.
+func (e *EthereumWorkloadGenerator) GenerateWorkloadSpecs() ([]WorkloadSpec, error) {
+	params := map[string]string{
+		ethereumSpecChainID:  e.ChainID.String(),
+		ethereumSpecGasPrice: e.SuggestedGasPrice.String(),
+	}
+
+	var functions map[string]string
+	switch e.BenchConfig.TxInfo.TxType {
+	case configs.TxTypeSimple:
+	case configs.TxTypeContract:
+		contractAddr, err := e.DeployContract(e.KnownAccounts[0].PrivateKey, e.BenchConfig.ContractInfo.Path)
+		if err != nil {
+			return nil, err
+		}
+		params[ethereumSpecContract] = contractAddr
+		functions = e.CompiledContract.Hashes
+	default:
+		return nil, ErrWorkloadSpecNotSupported
+	}
+
+	seed := e.BenchConfig.TxInfo.Generation.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+		zap.L().Info("Generated seed for the workload specs",
+			zap.Int64("seed", seed))
+	}
+
+	distribution := distributeAccounts(len(e.KnownAccounts), e.BenchConfig.Secondaries*e.BenchConfig.Threads)
+
+	specs := make([]WorkloadSpec, 0, e.BenchConfig.Secondaries)
+	txID := 0
+	worker := 0
+	for secondaryID := 0; secondaryID < e.BenchConfig.Secondaries; secondaryID++ {
+		spec := WorkloadSpec{
+			Secondary: secondaryID,
+			Seed:      seed + int64(secondaryID*e.BenchConfig.Threads),
+			Params:    params,
+			Functions: functions,
+		}
+
+		for thread := 0; thread < e.BenchConfig.Threads; thread++ {
+			choices := distribution[worker]
+			threadSpec := ThreadSpec{Intervals: e.TPSIntervals, FirstTx: txID}
+
+			// Count the transactions sent from each account, as they are
+			// picked by the index of the transaction in the whole workload
+			counts := make([]uint64, len(choices))
+			for _, numTx := range e.TPSIntervals {
+				for i := 0; i < numTx; i++ {
+					counts[txID%len(choices)]++
+					txID++
+				}
+			}
+
+			for i, account := range choices {
+				addr := strings.ToLower(e.KnownAccounts[account].Address)
+				threadSpec.Accounts = append(threadSpec.Accounts, AccountRange{
+					Account: account,
+					Nonce:   e.Nonces[addr],
+					Count:   counts[i],
+				})
+				e.Nonces[addr] += counts[i]
+			}
+
+			spec.Threads = append(spec.Threads, threadSpec)
+			worker++
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+// ExpandWorkloadSpec signs the transactions of the spec on the secondary, with
+// the chain parameters of the spec and the keys of the chain configuration.
+// The contract functions are drawn from the function ratios with the seed of each thread.
+func (e *EthereumWorkloadGenerator) ExpandWorkloadSpec(spec WorkloadSpec) (SecondaryWorkload, error) {
+	chainID, ok := big.NewInt(0).SetString(spec.Params[ethereumSpecChainID], 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid chain ID in workload spec: %s", spec.Params[ethereumSpecChainID])
+	}
+
+	gasPrice, ok := big.NewInt(0).SetString(spec.Params[ethereumSpecGasPrice], 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid gas price in workload spec: %s", spec.Params[ethereumSpecGasPrice])
+	}
+
+	e.ChainID = chainID
+	e.SuggestedGasPrice = gasPrice
+	e.KnownAccounts = e.ChainConfig.Keys
+	e.Nonces = make(map[string]uint64)
+	if len(spec.Functions) > 0 {
+		e.CompiledContract = &compiler.Contract{Hashes: spec.Functions}
+	}
+
+	functions := e.BenchConfig.ContractInfo.Functions
+	ratios := make([]int, len(functions))
+	for i, v := range functions {
+		ratios[i] = v.Ratio
+	}
+
+	secondaryWorkload := make(SecondaryWorkload, 0, len(spec.Threads))
+	for threadID, thread := range spec.Threads {
+		if len(thread.Accounts) == 0 {
+			return nil, fmt.Errorf("no accounts for thread %d in workload spec", threadID)
+		}
+
+		for _, v := range thread.Accounts {
+			if v.Account < 0 || v.Account >= len(e.KnownAccounts) {
+				return nil, fmt.Errorf("account %d of thread %d is not in the chain configuration", v.Account, threadID)
+			}
+			e.Nonces[strings.ToLower(e.KnownAccounts[v.Account].Address)] = v.Nonce
+		}
+
+		rng := rand.New(rand.NewSource(spec.ThreadSeed(threadID)))
+		txID := thread.FirstTx
+
+		threadWorkload := make(WorkerThreadWorkload, 0, len(thread.Intervals))
+		for _, numTx := range thread.Intervals {
+			intervalWorkload := make([][]byte, 0, numTx)
+
+			for i := 0; i < numTx; i++ {
+				accFrom := e.KnownAccounts[thread.Accounts[txID%len(thread.Accounts)].Account]
+
+				var tx []byte
+				var err error
+				switch e.BenchConfig.TxInfo.TxType {
+				case configs.TxTypeSimple:
+					accTo := e.KnownAccounts[thread.Accounts[(txID+1)%len(thread.Accounts)].Account]
+					tx, err = e.CreateSignedTransaction(
+						accFrom.PrivateKey,
+						accTo.Address,
+						big.NewInt(1000000),
+						[]byte{},
+					)
+				case configs.TxTypeContract:
+					if len(functions) == 0 {
+						return nil, fmt.Errorf("no contract functions to expand the workload spec")
+					}
+					funcToCreate := functions[weightedPick(rng, ratios)]
+					tx, err = e.CreateInteractionTX(
+						accFrom.PrivateKey,
+						spec.Params[ethereumSpecContract],
+						functionSignature(funcToCreate),
+						funcToCreate.Params,
+						funcToCreate.PayValue,
+					)
+				default:
+					return nil, ErrWorkloadSpecNotSupported
+				}
+
+				if err != nil {
+					return nil, err
+				}
+
+				intervalWorkload = append(intervalWorkload, tx)
+				txID++
+			}
+
+			threadWorkload = append(threadWorkload, intervalWorkload)
+		}
+
+		secondaryWorkload = append(secondaryWorkload, threadWorkload)
+	}
+
+	return secondaryWorkload, nil
+}
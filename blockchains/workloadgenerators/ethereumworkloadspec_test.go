@@ -0,0 +1,164 @@
+package workloadgenerators
+
+import (
+	"bytes"
+	"diablo-benchmark/core/configs"
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestGenerator returns an Ethereum generator with the chain parameters
+// set as InitParams would, without a connection to a node
+func newTestGenerator(t *testing.T, keys []configs.ChainKey, secondaries int, threads int) *EthereumWorkloadGenerator {
+	chainConfig := &configs.ChainConfig{Keys: keys}
+	benchConfig := &configs.BenchConfig{
+		Secondaries: secondaries,
+		Threads:     threads,
+		TxInfo:      configs.BenchInfo{TxType: configs.TxTypeSimple},
+	}
+
+	e := (&EthereumWorkloadGenerator{}).NewGenerator(chainConfig, benchConfig).(*EthereumWorkloadGenerator)
+	if err := e.BlockchainSetup(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.ChainID = big.NewInt(1337)
+	e.SuggestedGasPrice = big.NewInt(20000000000)
+	e.Nonces = make(map[string]uint64)
+	for i, key := range e.KnownAccounts {
+		e.Nonces[strings.ToLower(key.Address)] = uint64(10 * i)
+	}
+	e.SetThreadIntervals([]int{3, 5})
+
+	return e
+}
+
+func TestExpandWorkloadSpec(t *testing.T) {
+	var keys []configs.ChainKey
+	for i := 0; i < 3; i++ {
+		priv, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, configs.ChainKey{
+			PrivateKey: crypto.FromECDSA(priv),
+			Address:    crypto.PubkeyToAddress(priv.PublicKey).String(),
+		})
+	}
+
+	t.Run("expanded specs match the generated workload", func(t *testing.T) {
+		for _, threads := range []int{1, 2} {
+			workload, err := newTestGenerator(t, keys, 2, threads).generateSimpleWorkload()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			specs, err := newTestGenerator(t, keys, 2, threads).GenerateWorkloadSpecs()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, spec := range specs {
+				// Go through the encoding used to send the spec
+				data, err := json.Marshal(spec)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var decoded WorkloadSpec
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					t.Fatal(err)
+				}
+
+				secondary := newTestGenerator(t, keys, 2, threads)
+				expanded, err := secondary.ExpandWorkloadSpec(decoded)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if decoded.NumTransactions() != 8*threads {
+					t.Errorf("threads %d secondary %d: expected %d transactions, got %d", threads, i, 8*threads, decoded.NumTransactions())
+				}
+
+				for thread := range workload[i] {
+					for interval := range workload[i][thread] {
+						for tx := range workload[i][thread][interval] {
+							if !bytes.Equal(workload[i][thread][interval][tx], expanded[thread][interval][tx]) {
+								t.Fatalf("threads %d: transaction %d of interval %d of thread %d of secondary %d differs", threads, tx, interval, thread, i)
+							}
+						}
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		spec := WorkloadSpec{
+			Params:  map[string]string{ethereumSpecChainID: "1337", ethereumSpecGasPrice: "1"},
+			Threads: []ThreadSpec{{Intervals: []int{1}, Accounts: []AccountRange{{Account: 3}}}},
+		}
+
+		if _, err := newTestGenerator(t, keys, 1, 1).ExpandWorkloadSpec(spec); err == nil {
+			t.Error("expected an error for an account that is not in the chain configuration")
+		}
+	})
+}
+
+func TestWeightedPick(t *testing.T) {
+	t.Run("follows the weights", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		counts := make([]int, 3)
+		for i := 0; i < 10000; i++ {
+			counts[weightedPick(rng, []int{10, 0, 90})]++
+		}
+
+		if counts[1] != 0 {
+			t.Errorf("picked an index of zero weight %d times", counts[1])
+		}
+		if counts[0] < 800 || counts[0] > 1200 {
+			t.Errorf("expected about 1000 picks of the first index, got %d", counts[0])
+		}
+	})
+
+	t.Run("no positive weights", func(t *testing.T) {
+		if v := weightedPick(rand.New(rand.NewSource(1)), []int{0, 0}); v != 0 {
+			t.Errorf("expected 0, got %d", v)
+		}
+	})
+}
+
+func TestContractFunctions(t *testing.T) {
+	get := configs.ContractFunction{Name: "get()", Type: "read"}
+	store := configs.ContractFunction{Name: "storeVal", Type: "write", Params: []configs.ContractParam{{Type: "uint32", Value: "10"}}}
+
+	e := &EthereumWorkloadGenerator{
+		BenchConfig: &configs.BenchConfig{
+			TxInfo: configs.BenchInfo{TxType: configs.TxTypeContract},
+			ContractInfo: configs.ContractInfo{Functions: []configs.ContractFunction{
+				get,
+				store,
+				{Name: "()", Type: "write"},
+				{Name: "missing()", Type: "read"},
+			}},
+		},
+		CompiledContract: &compiler.Contract{Hashes: map[string]string{
+			"get()":            "6D4CE63C",
+			"storeVal(uint32)": "3a1a9c2b",
+		}},
+	}
+
+	functions := e.ContractFunctions()
+
+	if len(functions) != 2 {
+		t.Fatalf("expected 2 functions, got %v", functions)
+	}
+	if functions["0x6d4ce63c"].Name != get.Name || functions["0x3a1a9c2b"].Name != store.Name {
+		t.Errorf("expected the functions keyed by their compiled selectors, got %v", functions)
+	}
+}
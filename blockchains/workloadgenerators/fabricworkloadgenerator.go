@@ -526,3 +526,23 @@ func (f FabricWorkloadGenerator) GenerateWorkload() (Workload, error) {
 	}
 
 }
+
+// GenerateWorkloadSpecs is not supported, the Fabric workloads are always generated on the primary
+func (f FabricWorkloadGenerator) GenerateWorkloadSpecs() ([]WorkloadSpec, error) {
+	return nil, ErrWorkloadSpecNotSupported
+}
+
+// ExpandWorkloadSpec is not supported, the Fabric workloads are always generated on the primary
+func (f FabricWorkloadGenerator) ExpandWorkloadSpec(spec WorkloadSpec) (SecondaryWorkload, error) {
+	return nil, ErrWorkloadSpecNotSupported
+}
+
+// ContractFunctions returns the functions of the chaincode, the transactions record the name of the function they call
+func (f FabricWorkloadGenerator) ContractFunctions() map[string]configs.ContractFunction {
+	functions := make(map[string]configs.ContractFunction)
+	for _, v := range f.BenchConfig.ContractInfo.Functions {
+		functions[v.Name] = v
+	}
+
+	return functions
+}
@@ -69,6 +69,20 @@ type WorkloadGenerator interface {
 	// GenerateWorkload generates the workload specified in the chain configurations.
 	GenerateWorkload() (Workload, error)
 
+	// GenerateWorkloadSpecs generates a compact spec of the workload of each secondary,
+	// or ErrWorkloadSpecNotSupported if the workload can only be generated by GenerateWorkload.
+	GenerateWorkloadSpecs() ([]WorkloadSpec, error)
+
+	// ExpandWorkloadSpec expands the spec of a secondary into its signed transactions,
+	// this is called on the secondary. The workload has the same intervals, accounts and
+	// nonces as GenerateWorkload, but the transactions may differ where the generator draws
+	// them at random (e.g. the contract functions, drawn from their ratios with the seed).
+	ExpandWorkloadSpec(spec WorkloadSpec) (SecondaryWorkload, error)
+
+	// ContractFunctions returns the contract functions of the workload, keyed by the identifier
+	// the client interface records for the transactions calling them (e.g. the function selector).
+	ContractFunctions() map[string]configs.ContractFunction
+
 	// SetThreadIntervals sets the number of transactions per thread to create for each interval
 	SetThreadIntervals(interval []int)
 }
@@ -0,0 +1,94 @@
+package workloadgenerators
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// ErrWorkloadSpecNotSupported is returned by the generators that cannot
+// describe their workload as a spec, the workload is then generated on the primary.
+var ErrWorkloadSpecNotSupported = errors.New("workload generator does not support workload specs")
+
+// WorkloadSpec is the compact description of the workload of a secondary that
+// the secondary expands into the signed transactions locally, instead of
+// receiving all of them from the primary.
+type WorkloadSpec struct {
+	Secondary int               `json:"Secondary"` // ID of the secondary the spec is for
+	Seed      int64             `json:"Seed"`      // Seed of the function mix of the secondary
+	Threads   []ThreadSpec      `json:"Threads"`   // Spec of each worker thread of the secondary
+	Params    map[string]string `json:"Params"`    // Chain parameters needed to sign (e.g. chain ID, gas price, contract address)
+	Functions map[string]string `json:"Functions"` // Hashes of the contract functions by signature
+}
+
+// ThreadSpec is the spec of the workload of a worker thread
+type ThreadSpec struct {
+	Intervals []int          `json:"Intervals"` // Number of transactions in each interval
+	Accounts  []AccountRange `json:"Accounts"`  // Accounts the thread sends from, with the nonces it uses
+	FirstTx   int            `json:"FirstTx"`   // Index of the first transaction of the thread in the whole workload
+}
+
+// AccountRange is an account used by a thread and the range of nonces it uses
+type AccountRange struct {
+	Account int    `json:"Account"` // Index of the account in the keys of the chain configuration
+	Nonce   uint64 `json:"Nonce"`   // First nonce of the range
+	Count   uint64 `json:"Count"`   // Number of nonces in the range
+}
+
+// NumTransactions returns the number of transactions described by the spec
+func (s *WorkloadSpec) NumTransactions() int {
+	total := 0
+	for _, thread := range s.Threads {
+		for _, v := range thread.Intervals {
+			total += v
+		}
+	}
+
+	return total
+}
+
+// ThreadSeed returns the seed of the function mix of the given thread, so
+// that every thread of every secondary draws a different sequence.
+func (s *WorkloadSpec) ThreadSeed(thread int) int64 {
+	return s.Seed + int64(thread)
+}
+
+// distributeAccounts assigns the indices of the accounts to the workers in a
+// round robin, every worker gets at least one account and every account is
+// used at least once.
+func distributeAccounts(numAccounts int, numWorkers int) [][]int {
+	distribution := make([][]int, numWorkers)
+
+	for i := 0; i < numAccounts || i < numWorkers; i++ {
+		distribution[i%numWorkers] = append(distribution[i%numWorkers], i%numAccounts)
+	}
+
+	return distribution
+}
+
+// weightedPick picks an index at random with a probability proportional to
+// its weight. Returns 0 if none of the weights are positive.
+func weightedPick(rng *rand.Rand, weights []int) int {
+	total := 0
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	pick := rng.Intn(total)
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if pick < w {
+			return i
+		}
+		pick -= w
+	}
+
+	return len(weights) - 1
+}
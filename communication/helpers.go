@@ -20,3 +20,17 @@ func DecodeWorkload(data []byte) (workloadgenerators.SecondaryWorkload, error) {
 
 	return decodedWorkload, err
 }
+
+// EncodeWorkloadSpec encodes the workload spec of a secondary in the same way as the workloads
+func EncodeWorkloadSpec(spec workloadgenerators.WorkloadSpec) ([]byte, error) {
+	return json.Marshal(spec)
+}
+
+// DecodeWorkloadSpec decodes the workload spec of a secondary
+func DecodeWorkloadSpec(data []byte) (workloadgenerators.WorkloadSpec, error) {
+	var decodedSpec workloadgenerators.WorkloadSpec
+
+	err := json.Unmarshal(data, &decodedSpec)
+
+	return decodedSpec, err
+}
@@ -8,11 +8,12 @@ package communication
 
 // Communication Messages
 var (
-	MsgPrepare  = []byte("\x01") // Initialise the connection
-	MsgWorkload = []byte("\x02") // Workload message
-	MsgRun      = []byte("\x03") // Start the benchmark
-	MsgResults  = []byte("\x04") // Return the result request
-	MsgFin      = []byte("\x05") // Finish and close the connection.
-	MsgOk       = []byte("\x99") // Everything is OK
-	MsgErr      = []byte("\x98") // There was an error on the client
+	MsgPrepare      = []byte("\x01") // Initialise the connection
+	MsgWorkload     = []byte("\x02") // Workload message
+	MsgRun          = []byte("\x03") // Start the benchmark
+	MsgResults      = []byte("\x04") // Return the result request
+	MsgFin          = []byte("\x05") // Finish and close the connection.
+	MsgWorkloadSpec = []byte("\x06") // Workload spec message, expanded by the secondary
+	MsgOk           = []byte("\x99") // Everything is OK
+	MsgErr          = []byte("\x98") // There was an error on the client
 )
@@ -3,6 +3,7 @@ package communication
 import (
 	"encoding/binary"
 	"io"
+	"io/ioutil"
 	"net"
 
 	"go.uber.org/zap"
@@ -175,6 +176,34 @@ func (c *ConnClient) ReadSize(size uint64) ([]byte, error) {
 	return buf[:n], nil
 }
 
+// ReadFull reads the whole payload of the given size that follows the initial
+// read, however many reads it takes
+func (c *ConnClient) ReadFull(size uint64) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(c.Conn, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// PayloadReader returns a reader of the payload of the given size that follows
+// the initial read, so that large payloads can be streamed rather than held in
+// memory. The payload must be read to the end before the next command.
+func (c *ConnClient) PayloadReader(size uint64) io.Reader {
+	zap.L().Debug("Streaming payload",
+		zap.Uint64("size", size))
+
+	return io.LimitReader(c.Conn, int64(size))
+}
+
+// DiscardPayload reads the rest of a payload streamed from PayloadReader, so
+// that the next command is read from its start
+func (c *ConnClient) DiscardPayload(r io.Reader) error {
+	_, err := io.Copy(ioutil.Discard, r)
+	return err
+}
+
 // CloseConn closes the connection to the primary server
 func (c *ConnClient) CloseConn() {
 	zap.L().Debug("Closing Connection to primary")
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 
 	"go.uber.org/zap"
 )
@@ -66,6 +67,21 @@ func (s *PrimaryServer) HandleSecondaries(readyChannel chan bool) {
 	}
 }
 
+// WithSecondaries returns a view of the server that only communicates with the
+// first n connected secondaries. This is used to run a benchmark on a subset of
+// the connected secondaries.
+func (s *PrimaryServer) WithSecondaries(n int) *PrimaryServer {
+	if n <= 0 || n > len(s.Secondaries) {
+		n = len(s.Secondaries)
+	}
+
+	return &PrimaryServer{
+		Listener:            s.Listener,
+		Secondaries:         s.Secondaries[:n],
+		ExpectedSecondaries: n,
+	}
+}
+
 // sendAndWaitOKAsync is used to send and wait for the OK byte to be
 // received. This takes a channel and replies on the channel once OK or err is received.
 func (s *PrimaryServer) sendAndWaitOKAsync(data []byte, secondary net.Conn, doneCh chan int, errCh chan error) {
@@ -231,19 +247,26 @@ func (s *PrimaryServer) sendAndWaitData(data []byte, secondary net.Conn) ([]resu
 	return res, nil
 }
 
-// PrepareBenchmarkSecondaries sends the prepare message to the secondaires
-func (s *PrimaryServer) PrepareBenchmarkSecondaries(numThreads uint32) SecondaryReplyErrors {
+// PrepareBenchmarkSecondaries sends the prepare message to the secondaires,
+// followed by the identifier of the run shared with their hooks
+func (s *PrimaryServer) PrepareBenchmarkSecondaries(numThreads uint32, runID string) SecondaryReplyErrors {
 
 	var errorList []string
 
 	threadBytes := make([]byte, 4)
 	binary.BigEndian.PutUint32(threadBytes, numThreads)
 
+	// format: len, run ID
+	runIDLenBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(runIDLenBytes, uint64(len(runID)))
+
 	for i, c := range s.Secondaries {
 		secondaryID := make([]byte, 4)
 		binary.BigEndian.PutUint32(secondaryID, uint32(i))
 		payload := append(MsgPrepare, secondaryID...)
 		payload = append(payload, threadBytes...)
+		payload = append(payload, runIDLenBytes...)
+		payload = append(payload, []byte(runID)...)
 		err := s.SendAndWaitOKSync(payload, c)
 		if err != nil {
 			zap.L().Warn("Got an error from secondary",
@@ -294,6 +317,57 @@ func (s *PrimaryServer) SendWorkload(workloads workloadgenerators.Workload) Seco
 	return nil
 }
 
+// SendWorkloadSpecs sends the workload spec of each secondary, which the
+// secondary expands into its workload. Uses the same framing as SendWorkload.
+// The specs are sent to all secondaries at once so that they expand their
+// workloads concurrently, the replies are then waited for.
+func (s *PrimaryServer) SendWorkloadSpecs(specs []workloadgenerators.WorkloadSpec) SecondaryReplyErrors {
+	var errorList SecondaryReplyErrors
+
+	messages := make([][]byte, len(s.Secondaries))
+	for i := range s.Secondaries {
+		payload, err := EncodeWorkloadSpec(specs[i])
+		if err != nil {
+			return append(errorList, err.Error())
+		}
+
+		// format: cmd, len, payload
+		payloadLenBytes := make([]byte, 8)
+		binary.BigEndian.PutUint64(payloadLenBytes, uint64(len(payload)))
+
+		data := append([]byte{}, MsgWorkloadSpec...)
+		data = append(data, payloadLenBytes...)
+		messages[i] = append(data, payload...)
+	}
+
+	errs := make([]error, len(s.Secondaries))
+	var wg sync.WaitGroup
+	for i, c := range s.Secondaries {
+		zap.L().Debug("Sending workload spec",
+			zap.Int("secondary", i),
+			zap.Int("length", len(messages[i])))
+
+		wg.Add(1)
+		go func(i int, c net.Conn) {
+			defer wg.Done()
+			errs[i] = s.SendAndWaitOKSync(messages[i], c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			errorList = append(errorList, err.Error())
+		}
+	}
+
+	if len(errorList) == 0 {
+		return nil
+	}
+
+	return errorList
+}
+
 // RunBenchmark sends the message to all secondaries to run the benchmark.
 func (s *PrimaryServer) RunBenchmark() SecondaryReplyErrors {
 	zap.L().Info("\n------------\nStarting Benchmark\n------------\n")
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"diablo-benchmark/core/results"
 	"flag"
 	"os"
 
@@ -12,17 +13,24 @@ import (
 type Arguments struct {
 	PrimaryCommand   *flag.FlagSet  // Commands related to the primary
 	SecondaryCommand *flag.FlagSet  // Commands related to the secondarys
+	CompareCommand   *flag.FlagSet  // Commands related to the comparison of results
+	MergeCommand     *flag.FlagSet  // Commands related to the merge of the results of the secondaries
 	PrimaryArgs      *PrimaryArgs   // Primary arguments
 	SecondaryArgs    *SecondaryArgs // Secondary arguments
+	CompareArgs      *CompareArgs   // Comparison arguments
+	MergeArgs        *MergeArgs     // Merge arguments
 }
 
 // PrimaryArgs contains the command-line arguments for the primary
 type PrimaryArgs struct {
-	BenchConfigPath string        // Path to the configurations
-	ChainConfigPath string        // Path to the chain configuration
-	ListenAddr      string        // host:port that it should run on
-	LogLevel        zapcore.Level // log level
-	Timeout         int           // benchmark timeout
+	BenchConfigPath string                 // Path to the configurations
+	ChainConfigPath string                 // Path to the chain configuration
+	ListenAddr      string                 // host:port that it should run on
+	LogLevel        zapcore.Level          // log level
+	Timeout         int                    // benchmark timeout
+	Export          string                 // Comma separated formats to export the results to
+	Exports         []results.ExportFormat // Formats to export the results to, parsed from Export
+	MetricsAddr     string                 // host:port to serve the Prometheus metrics on (empty to not serve them)
 }
 
 // SecondaryArgs provides command-line arguments for secondary
@@ -32,6 +40,22 @@ type SecondaryArgs struct {
 	PrimaryAddr     string        // Address of the primary (can also be in secondary config)
 	LogLevel        zapcore.Level // log level
 	Timeout         int           // benchmark timeout
+	SpoolDir        string        // Directory to spool the workload to (empty keeps it in memory)
+	MetricsAddr     string        // host:port to serve the Prometheus metrics on (empty to not serve them)
+}
+
+// CompareArgs provides command-line arguments for the comparison of results
+type CompareArgs struct {
+	Threshold         float64 // Change (%) in the worse direction from which a metric regressed
+	AbsoluteThreshold float64 // Difference in the worse direction from which a metric compared as a difference (failure rate) regressed
+}
+
+// MergeArgs provides command-line arguments for the merge of the results of the secondaries
+type MergeArgs struct {
+	BenchConfigPath string                 // Path to the benchmark configuration of the run
+	ChainConfigPath string                 // Path to the chain configuration of the run
+	Export          string                 // Comma separated formats to export the results to
+	Exports         []results.ExportFormat // Formats to export the results to, parsed from Export
 }
 
 // DefineArguments sets the arguments that will be used for the subcommands
@@ -39,9 +63,13 @@ func DefineArguments() *Arguments {
 
 	primaryCommand := flag.NewFlagSet("primary", flag.ExitOnError)
 	secondaryCommand := flag.NewFlagSet("secondary", flag.ExitOnError)
+	compareCommand := flag.NewFlagSet("compare", flag.ExitOnError)
+	mergeCommand := flag.NewFlagSet("merge", flag.ExitOnError)
 
 	primaryArgs := PrimaryArgs{}
 	secondaryArgs := SecondaryArgs{}
+	compareArgs := CompareArgs{}
+	mergeArgs := MergeArgs{}
 
 	// General arguments
 	// --config
@@ -63,6 +91,10 @@ func DefineArguments() *Arguments {
 	secondaryArgs.LogLevel = zapcore.InfoLevel
 	secondaryCommand.Var(&secondaryArgs.LogLevel, "level", "--level INFO|WARN|DEBUG|ERROR")
 
+	// --metrics
+	primaryCommand.StringVar(&primaryArgs.MetricsAddr, "metrics", "", "--metrics=addr (e.g. --metrics=\":9090\", serve Prometheus metrics)")
+	secondaryCommand.StringVar(&secondaryArgs.MetricsAddr, "metrics", "", "--metrics=addr (e.g. --metrics=\":9091\", serve Prometheus metrics)")
+
 	// Primary Arguments
 	primaryCommand.StringVar(&primaryArgs.ListenAddr, "addr", "", "--addr=addr (e.g. --addr=\"0.0.0.0:8323\")")
 	primaryCommand.StringVar(&primaryArgs.ListenAddr, "a", "", "-a addr (e.g. -a \":8323\")")
@@ -70,6 +102,8 @@ func DefineArguments() *Arguments {
 	primaryCommand.StringVar(&primaryArgs.ChainConfigPath, "chain-config", "", "--chain-config=/path/to/chain/yml (required)")
 	primaryCommand.StringVar(&primaryArgs.ChainConfigPath, "cc", "", "-cc /path/to/chain/yml")
 
+	primaryCommand.StringVar(&primaryArgs.Export, "export", "", "--export=csv,intervals,trace (export the results)")
+
 	// Secondary Arguments
 	secondaryCommand.StringVar(&secondaryArgs.PrimaryAddr, "primary", "", "--primary=<ipaddr>:<port>")
 	secondaryCommand.StringVar(&secondaryArgs.PrimaryAddr, "m", "", "-m <ipaddress>:<port>")
@@ -77,12 +111,29 @@ func DefineArguments() *Arguments {
 	secondaryCommand.StringVar(&secondaryArgs.ChainConfigPath, "chain-config", "", "--chain-config=/path/to/chain/yml (required)")
 	secondaryCommand.StringVar(&secondaryArgs.ChainConfigPath, "cc", "", "-cc /path/to/chain/yml")
 
+	secondaryCommand.StringVar(&secondaryArgs.SpoolDir, "spool", "", "--spool=/path/to/dir (spool the workload to disk)")
+
+	// Compare Arguments
+	compareCommand.Float64Var(&compareArgs.Threshold, "threshold", results.DefaultRegressionThreshold, "--threshold=<percent> (regression threshold)")
+	compareCommand.Float64Var(&compareArgs.AbsoluteThreshold, "absolute-threshold", results.DefaultAbsoluteRegressionThreshold, "--absolute-threshold=<points> (regression threshold of the failure rate)")
+
+	// Merge Arguments
+	mergeCommand.StringVar(&mergeArgs.BenchConfigPath, "config", "", "--config=/path/to/config (required)")
+	mergeCommand.StringVar(&mergeArgs.BenchConfigPath, "c", "", "-c /path/to/config")
+	mergeCommand.StringVar(&mergeArgs.ChainConfigPath, "chain-config", "", "--chain-config=/path/to/chain/yml (required)")
+	mergeCommand.StringVar(&mergeArgs.ChainConfigPath, "cc", "", "-cc /path/to/chain/yml")
+	mergeCommand.StringVar(&mergeArgs.Export, "export", "", "--export=csv,intervals,trace (export the results)")
+
 	// Return all the arguments
 	return &Arguments{
 		PrimaryCommand:   primaryCommand,   // The primary command FlagSet
 		SecondaryCommand: secondaryCommand, // The secondary command FlagSet
+		CompareCommand:   compareCommand,   // The compare command FlagSet
+		MergeCommand:     mergeCommand,     // The merge command FlagSet
 		PrimaryArgs:      &primaryArgs,     // The primary argument list, contains config and other args
 		SecondaryArgs:    &secondaryArgs,   // The secondary argument list, contains config and other args
+		CompareArgs:      &compareArgs,     // The compare argument list, contains the threshold
+		MergeArgs:        &mergeArgs,       // The merge argument list, contains the configs of the run
 	}
 }
 
@@ -97,6 +148,14 @@ func (pa *PrimaryArgs) CheckArgs() {
 		zap.L().Error("chain configuration not provided")
 		os.Exit(1)
 	}
+
+	exports, err := results.ParseExportFormats(pa.Export)
+	if err != nil {
+		zap.L().Error("invalid export formats",
+			zap.Error(err))
+		os.Exit(1)
+	}
+	pa.Exports = exports
 }
 
 // SecondaryArgs validates that the secondary arguments are correct
@@ -117,3 +176,24 @@ func (sa *SecondaryArgs) SecondaryArgs() {
 		os.Exit(1)
 	}
 }
+
+// CheckArgs checks that the merge arguments conform to specified requirements
+func (ma *MergeArgs) CheckArgs() {
+	if ma.BenchConfigPath == "" {
+		zap.L().Error("benchmark config not provided")
+		os.Exit(1)
+	}
+
+	if ma.ChainConfigPath == "" {
+		zap.L().Error("chain configuration not provided")
+		os.Exit(1)
+	}
+
+	exports, err := results.ParseExportFormats(ma.Export)
+	if err != nil {
+		zap.L().Error("invalid export formats",
+			zap.Error(err))
+		os.Exit(1)
+	}
+	ma.Exports = exports
+}
@@ -14,16 +14,58 @@ type BenchConfig struct {
 	Threads      int          `yaml:"threads"`               // Number of threads per secondary expected.
 	Secondaries  int          `yaml:"secondaries"`           // Number of secondary machines.
 	Timeout      int          `yaml:"timeout"`               // Timeout for the benchmark after sending
+	Completion   Completion   `yaml:"completion,omitempty"`  // Criteria ending the wait for transactions after sending
 	TxInfo       BenchInfo    `yaml:"bench,flow"`            // Benchmark transaction information.
 	ContractInfo ContractInfo `yaml:"contract,omitempty"`    // Contract Information
+	Assertions   Assertions   `yaml:"assertions,omitempty"`  // Pass/fail criteria checked against the results
+	Results      ResultsInfo  `yaml:"results,omitempty"`     // What the secondaries return in the results
+	Hooks        []HookConfig `yaml:"hooks,omitempty"`       // Commands run at each phase of the benchmark
+	Sweep        SweepConfig  `yaml:"sweep,omitempty"`       // Parameter ranges to run the benchmark over
 }
 
 // BenchInfo provides specific information about transaction type and intervals
 type BenchInfo struct {
-	TxType      BenchTransactionType              `yaml:"type"`               // Type of the transactions (simple, contract).
-	DataPath    string                            `yaml:"datapath,omitempty"` // Data path of the transactions
-	Intervals   TPSIntervals                      `yaml:"txs"`                // Transactions.
-	PremadeInfo workload.PremadeBenchmarkWorkload // Premade workload (if exists)
+	TxType       BenchTransactionType              `yaml:"type"`                    // Type of the transactions (simple, contract).
+	DataPath     string                            `yaml:"datapath,omitempty"`      // Data path of the transactions
+	Intervals    TPSIntervals                      `yaml:"txs"`                     // Transactions.
+	Pacing       PacingConfig                      `yaml:"pacing,omitempty"`        // Pacing of the transactions within each interval
+	Arrival      ArrivalConfig                     `yaml:"arrival,omitempty"`       // Arrival process of the transactions within each interval
+	Load         LoadConfig                        `yaml:"load,omitempty"`          // Open or closed loop load
+	LagThreshold int                               `yaml:"lag_threshold,omitempty"` // Lag behind the schedule in milliseconds above which an interval is flagged
+	Generation   GenerationConfig                  `yaml:"generation,omitempty"`    // Where the transactions are generated
+	PremadeInfo  workload.PremadeBenchmarkWorkload // Premade workload (if exists)
+}
+
+// PacingConfig defines how the transactions of each (one second) interval are
+// spread over the interval by the secondaries.
+type PacingConfig struct {
+	Mode        PacingMode `yaml:"mode,omitempty"`        // Pacing mode: burst (default), even or step
+	Granularity int        `yaml:"granularity,omitempty"` // Length of each step in milliseconds (step mode)
+}
+
+// ArrivalConfig defines the arrival process that determines the send time of
+// each transaction within an interval. The number of transactions per interval
+// is always the configured rate.
+type ArrivalConfig struct {
+	Process ArrivalProcess `yaml:"process,omitempty"` // Arrival process: constant (default), poisson or bursty
+	Seed    int64          `yaml:"seed,omitempty"`    // Seed of the random arrivals, 0 picks (and logs) a random seed
+	On      int            `yaml:"on,omitempty"`      // Length of the on periods in milliseconds (bursty)
+	Off     int            `yaml:"off,omitempty"`     // Length of the off periods in milliseconds (bursty)
+}
+
+// LoadConfig defines whether the load is open loop (scheduled sends) or closed
+// loop, where each worker has at most Outstanding transactions in flight.
+type LoadConfig struct {
+	Mode        LoadMode `yaml:"mode,omitempty"`        // Load mode: open (default) or closed
+	Outstanding int      `yaml:"outstanding,omitempty"` // Maximum outstanding transactions per worker (closed mode)
+	ThinkTime   int      `yaml:"think_time,omitempty"`  // Time in milliseconds between a completion and the next send (closed mode)
+}
+
+// GenerationConfig defines where the transactions of the workload are
+// generated and signed, and the seed of the function mix of the secondaries.
+type GenerationConfig struct {
+	Mode GenerationMode `yaml:"mode,omitempty"` // Generation mode: primary (default) or secondary
+	Seed int64          `yaml:"seed,omitempty"` // Seed of the function mix (secondary), 0 picks (and logs) a random seed
 }
 
 // ContractParam defines the contract function parameters
@@ -47,3 +89,54 @@ type ContractInfo struct {
 	Name      string             `yaml:"name"`           // The contract name (required for multiple deployed contracts)
 	Functions []ContractFunction `yaml:"functions,flow"` // Functions that should be called.
 }
+
+// Completion defines the criteria that end the benchmark before the timeout.
+// A zero value disables the criterion.
+type Completion struct {
+	CommittedFraction float64 `yaml:"committed_fraction,omitempty"` // Fraction (0 - 1] of the sent transactions that must be committed
+	Idle              int     `yaml:"idle,omitempty"`               // Seconds without any newly done transaction
+	Deadline          int     `yaml:"deadline,omitempty"`           // Seconds from the start of the benchmark, stops sending if reached
+}
+
+// ResultsInfo defines what the secondaries return in their results
+type ResultsInfo struct {
+	Latencies        LatencyFormat `yaml:"latencies,omitempty"`         // Latency of every transaction (raw, default) or a histogram
+	ThroughputWindow int           `yaml:"throughput_window,omitempty"` // Size of the windows of the throughput over time in milliseconds
+}
+
+// Assertions defines the pass/fail criteria (SLOs) of the benchmark that are
+// evaluated against the aggregated results once the benchmark is complete.
+// Any assertion that is not defined is not checked.
+type Assertions struct {
+	MinThroughput        *float64 `yaml:"min_throughput,omitempty"`         // Minimum average throughput [tx/sec]
+	MaxP99Latency        *float64 `yaml:"max_p99_latency,omitempty"`        // Maximum 99th percentile latency [ms]
+	MaxFailureRatio      *float64 `yaml:"max_failure_ratio,omitempty"`      // Maximum ratio of failed transactions (0 - 1)
+	MinCommittedFraction *float64 `yaml:"min_committed_fraction,omitempty"` // Minimum fraction of the workload committed (0 - 1)
+}
+
+// HookConfig defines a shell command that is run at a phase of the benchmark.
+// The command is run with environment variables describing the run, and its
+// output is captured in the results directory.
+type HookConfig struct {
+	Phase    HookPhase `yaml:"phase"`              // Phase to run the hook at (setup, workload, run_start, run_end, results)
+	Command  string    `yaml:"command"`            // Shell command to execute
+	RunOn    string    `yaml:"on,omitempty"`       // Where to run the hook: primary (default), secondary, all
+	Timeout  int       `yaml:"timeout,omitempty"`  // Timeout of the command in seconds, 0 for no timeout
+	Required bool      `yaml:"required,omitempty"` // Abort the benchmark if the hook fails
+}
+
+// SweepConfig defines the parameter ranges of a sweep campaign. The benchmark is
+// run once for every combination of the values, parameters that are not defined
+// keep the value of the benchmark configuration.
+type SweepConfig struct {
+	Threads     []int `yaml:"threads,omitempty,flow"`     // Number of threads per secondary
+	Secondaries []int `yaml:"secondaries,omitempty,flow"` // Number of secondaries (at most the configured secondaries)
+	TPS         []int `yaml:"tps,omitempty,flow"`         // Constant rate of transactions per second across all secondaries
+}
+
+// SweepPoint is a single combination of the parameters in a sweep
+type SweepPoint struct {
+	Threads     int `json:"Threads"`     // Number of threads per secondary
+	Secondaries int `json:"Secondaries"` // Number of secondaries
+	TPS         int `json:"TPS"`         // Constant rate of transactions per second, 0 if the configured intervals are used
+}
@@ -7,6 +7,8 @@ type ChainConfig struct {
 	Nodes            []string      `yaml:nodes`                // Address of the nodes.
 	KeyFile          string        `yaml:"key_file,omitempty"` // JSON file with privkey:address pairs
 	ThroughputWindow int           `yaml:"window"`             // Window for thropughput calculation (default 1s)
+	SendConcurrency  int           `yaml:"send_concurrency"`   // Maximum concurrent sends per connection
+	Confirmations    int           `yaml:"confirmations"`      // Blocks on top of the block of a transaction for it to be final
 	Keys             []ChainKey    `yaml:keys,flow`            // Key information
 	Extra            []interface{} `yaml:"extra,flow,omitempty"`
 }
@@ -101,5 +101,13 @@ func parseChainYaml(fileContents []byte, path string) (*configs.ChainConfig, err
 		chainConfig.ThroughputWindow = 1
 	}
 
+	if chainConfig.SendConcurrency <= 0 {
+		chainConfig.SendConcurrency = configs.DefaultSendConcurrency
+	}
+
+	if chainConfig.Confirmations < 0 {
+		chainConfig.Confirmations = 0
+	}
+
 	return &chainConfig, nil
 }
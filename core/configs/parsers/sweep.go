@@ -0,0 +1,73 @@
+package parsers
+
+import (
+	"diablo-benchmark/core/configs"
+	"fmt"
+)
+
+// SweepCombination is a single benchmark of a sweep campaign, the benchmark
+// configuration is the base configuration with the parameters of the point.
+type SweepCombination struct {
+	Point  configs.SweepPoint   // Parameters of this combination
+	Config *configs.BenchConfig // Benchmark configuration to run
+}
+
+// HasSweep returns true if the benchmark configuration defines a sweep campaign
+func HasSweep(config *configs.BenchConfig) bool {
+	return len(config.Sweep.Threads) > 0 ||
+		len(config.Sweep.Secondaries) > 0 ||
+		len(config.Sweep.TPS) > 0
+}
+
+// ExpandSweep expands the base benchmark configuration over the sweep
+// parameters, returning a benchmark configuration for every combination of
+// threads, secondaries and TPS, in that order of nesting.
+func ExpandSweep(base *configs.BenchConfig) []SweepCombination {
+	threads := base.Sweep.Threads
+	if len(threads) == 0 {
+		threads = []int{base.Threads}
+	}
+
+	secondaries := base.Sweep.Secondaries
+	if len(secondaries) == 0 {
+		secondaries = []int{base.Secondaries}
+	}
+
+	// A rate of 0 keeps the intervals of the base configuration
+	tpsValues := base.Sweep.TPS
+	if len(tpsValues) == 0 {
+		tpsValues = []int{0}
+	}
+
+	var combinations []SweepCombination
+	for _, t := range threads {
+		for _, s := range secondaries {
+			for _, tps := range tpsValues {
+				config := *base
+				config.Threads = t
+				config.Secondaries = s
+				config.Name = fmt.Sprintf("%s [threads=%d, secondaries=%d, tps=%d]", base.Name, t, s, tps)
+
+				// Replace every interval with the constant rate
+				if tps > 0 {
+					intervals := make(configs.TPSIntervals, len(base.TxInfo.Intervals))
+					for k := range base.TxInfo.Intervals {
+						intervals[k] = tps
+					}
+					config.TxInfo.Intervals = intervals
+				}
+
+				combinations = append(combinations, SweepCombination{
+					Point: configs.SweepPoint{
+						Threads:     t,
+						Secondaries: s,
+						TPS:         tps,
+					},
+					Config: &config,
+				})
+			}
+		}
+	}
+
+	return combinations
+}
@@ -26,6 +26,113 @@ const (
 	TxTypeContention = "contention"
 )
 
+// PacingMode defines how the transactions of an interval are spread over the interval
+type PacingMode string
+
+const (
+	// PacingBurst sends all transactions of the interval at the start of the interval
+	PacingBurst PacingMode = "burst"
+	// PacingEven spreads the transactions of the interval evenly over the interval
+	PacingEven PacingMode = "even"
+	// PacingStep splits the interval into steps of the configured granularity
+	// and spreads the transactions evenly over the steps
+	PacingStep PacingMode = "step"
+)
+
+// ArrivalProcess defines the process that determines the send time of each
+// transaction within an interval
+type ArrivalProcess string
+
+const (
+	// ArrivalConstant sends the transactions at the times given by the pacing
+	ArrivalConstant ArrivalProcess = "constant"
+	// ArrivalPoisson sends the transactions as a Poisson process over the interval
+	ArrivalPoisson ArrivalProcess = "poisson"
+	// ArrivalBursty alternates between on and off periods, the transactions
+	// are spread over the on periods only
+	ArrivalBursty ArrivalProcess = "bursty"
+)
+
+// LoadMode defines how the workers offer load to the blockchain
+type LoadMode string
+
+const (
+	// LoadOpen sends the transactions at their scheduled times regardless of completions
+	LoadOpen LoadMode = "open"
+	// LoadClosed keeps a bounded number of transactions outstanding per worker,
+	// sending the next one only once a previous one completed
+	LoadClosed LoadMode = "closed"
+)
+
+// GenerationMode defines where the signed transactions of the workload are generated
+type GenerationMode string
+
+const (
+	// GenerationPrimary generates and signs all the transactions on the
+	// primary, which sends them to the secondaries
+	GenerationPrimary GenerationMode = "primary"
+	// GenerationSecondary sends a compact spec of the workload to each
+	// secondary, which expands it into signed transactions locally
+	GenerationSecondary GenerationMode = "secondary"
+)
+
+// LatencyFormat defines how the secondaries return the latencies of the transactions
+type LatencyFormat string
+
+const (
+	// LatencyFormatRaw returns the latency of every transaction
+	LatencyFormatRaw LatencyFormat = "raw"
+	// LatencyFormatHistogram returns a compact histogram of the latencies only
+	LatencyFormatHistogram LatencyFormat = "histogram"
+)
+
+// HookPhase is a phase of the benchmark at which the lifecycle hooks are run
+type HookPhase string
+
+const (
+	// HookPhaseSetup runs once the blockchain is set up and the secondaries are prepared
+	HookPhaseSetup HookPhase = "setup"
+	// HookPhaseWorkload runs once the workload has been generated (primary) or received (secondary)
+	HookPhaseWorkload HookPhase = "workload"
+	// HookPhaseRunStart runs immediately before the benchmark starts sending transactions
+	HookPhaseRunStart HookPhase = "run_start"
+	// HookPhaseRunEnd runs once the benchmark has completed
+	HookPhaseRunEnd HookPhase = "run_end"
+	// HookPhaseResults runs once the results have been written (primary) or sent (secondary)
+	HookPhaseResults HookPhase = "results"
+)
+
+// HookPhases lists all phases of the benchmark in the order that they are run
+var HookPhases = []HookPhase{
+	HookPhaseSetup,
+	HookPhaseWorkload,
+	HookPhaseRunStart,
+	HookPhaseRunEnd,
+	HookPhaseResults,
+}
+
+// Where the lifecycle hooks are run
+const (
+	// HookOnPrimary runs the hook only on the primary (default)
+	HookOnPrimary = "primary"
+	// HookOnSecondary runs the hook only on the secondaries
+	HookOnSecondary = "secondary"
+	// HookOnAll runs the hook on both the primary and the secondaries
+	HookOnAll = "all"
+)
+
+// DefaultLagThreshold is the default lag behind the schedule in milliseconds
+// above which an interval is flagged
+const DefaultLagThreshold int = 100
+
+// DefaultThroughputWindow is the default size of the windows of the
+// throughput over time in milliseconds
+const DefaultThroughputWindow int = 1000
+
+// DefaultSendConcurrency is the default maximum number of concurrent sends on
+// each blockchain connection
+const DefaultSendConcurrency int = 64
+
 // DefaultTimeout is the default timeout for the benchmark if not provided
 // or overwritten by the args
 const DefaultTimeout int = 20
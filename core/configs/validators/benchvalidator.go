@@ -91,5 +91,229 @@ func ValidateBenchConfig(c *configs.BenchConfig) (bool, error) {
 		}
 	}
 
+	// Check the pacing of the transactions.
+	if ok, err := validatePacing(c); !ok {
+		return false, err
+	}
+
+	// Check the load mode.
+	if ok, err := validateLoad(c); !ok {
+		return false, err
+	}
+
+	// Check where the workload is generated.
+	if ok, err := validateGeneration(c); !ok {
+		return false, err
+	}
+
+	// Check the completion criteria.
+	if ok, err := validateCompletion(c); !ok {
+		return false, err
+	}
+
+	// Check the results returned by the secondaries.
+	if ok, err := validateResults(c); !ok {
+		return false, err
+	}
+
+	// Check the assertions are within range.
+	if ok, err := validateAssertions(c); !ok {
+		return false, err
+	}
+
+	// Check the lifecycle hooks.
+	if ok, err := validateHooks(c); !ok {
+		return false, err
+	}
+
+	// Check the sweep parameters.
+	if ok, err := validateSweep(c); !ok {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// validateAssertions checks that the pass/fail assertions defined in the
+// benchmark are within the range of values they can take.
+func validateAssertions(c *configs.BenchConfig) (bool, error) {
+	a := c.Assertions
+
+	if a.MinThroughput != nil && *a.MinThroughput < 0 {
+		return false, fmt.Errorf("[%s] assertion min_throughput cannot be negative", c.Name)
+	}
+
+	if a.MaxP99Latency != nil && *a.MaxP99Latency < 0 {
+		return false, fmt.Errorf("[%s] assertion max_p99_latency cannot be negative", c.Name)
+	}
+
+	if a.MaxFailureRatio != nil && (*a.MaxFailureRatio < 0 || *a.MaxFailureRatio > 1) {
+		return false, fmt.Errorf("[%s] assertion max_failure_ratio must be between 0 and 1", c.Name)
+	}
+
+	if a.MinCommittedFraction != nil && (*a.MinCommittedFraction < 0 || *a.MinCommittedFraction > 1) {
+		return false, fmt.Errorf("[%s] assertion min_committed_fraction must be between 0 and 1", c.Name)
+	}
+
+	return true, nil
+}
+
+// validateHooks checks that the lifecycle hooks have a command and run at a
+// known phase and location.
+func validateHooks(c *configs.BenchConfig) (bool, error) {
+	for i, h := range c.Hooks {
+		if len(h.Command) == 0 {
+			return false, fmt.Errorf("[%s] hook %d has no command", c.Name, i)
+		}
+
+		knownPhase := false
+		for _, p := range configs.HookPhases {
+			if h.Phase == p {
+				knownPhase = true
+			}
+		}
+		if !knownPhase {
+			return false, fmt.Errorf("[%s] hook %d has unknown phase \"%s\"", c.Name, i, h.Phase)
+		}
+
+		switch h.RunOn {
+		case "", configs.HookOnPrimary, configs.HookOnSecondary, configs.HookOnAll:
+		default:
+			return false, fmt.Errorf("[%s] hook %d has unknown location \"%s\"", c.Name, i, h.RunOn)
+		}
+
+		if h.Timeout < 0 {
+			return false, fmt.Errorf("[%s] hook %d timeout cannot be negative", c.Name, i)
+		}
+	}
+
+	return true, nil
+}
+
+// validateSweep checks that the sweep parameters are positive, and that the
+// number of secondaries does not exceed the secondaries that will connect.
+func validateSweep(c *configs.BenchConfig) (bool, error) {
+	for _, v := range c.Sweep.Threads {
+		if v <= 0 {
+			return false, fmt.Errorf("[%s] sweep threads must be minimum 1", c.Name)
+		}
+	}
+
+	for _, v := range c.Sweep.Secondaries {
+		if v <= 0 || v > c.Secondaries {
+			return false, fmt.Errorf("[%s] sweep secondaries must be between 1 and %d", c.Name, c.Secondaries)
+		}
+	}
+
+	for _, v := range c.Sweep.TPS {
+		if v < 0 {
+			return false, fmt.Errorf("[%s] sweep tps cannot be negative", c.Name)
+		}
+	}
+
+	return true, nil
+}
+
+// validatePacing checks that the pacing mode is known and that the step mode
+// has a granularity within the one second interval, as well as the arrival process.
+func validatePacing(c *configs.BenchConfig) (bool, error) {
+	pacing := c.TxInfo.Pacing
+
+	switch pacing.Mode {
+	case "", configs.PacingBurst, configs.PacingEven:
+	case configs.PacingStep:
+		if pacing.Granularity <= 0 || pacing.Granularity > 1000 {
+			return false, fmt.Errorf("[%s] pacing granularity must be between 1 and 1000 milliseconds", c.Name)
+		}
+	default:
+		return false, fmt.Errorf("[%s] unknown pacing mode \"%s\"", c.Name, pacing.Mode)
+	}
+
+	arrival := c.TxInfo.Arrival
+
+	switch arrival.Process {
+	case "", configs.ArrivalConstant, configs.ArrivalPoisson:
+	case configs.ArrivalBursty:
+		if arrival.On <= 0 || arrival.Off < 0 {
+			return false, fmt.Errorf("[%s] bursty arrival requires a positive on period and non-negative off period", c.Name)
+		}
+	default:
+		return false, fmt.Errorf("[%s] unknown arrival process \"%s\"", c.Name, arrival.Process)
+	}
+
+	return true, nil
+}
+
+// validateLoad checks that the load mode is known, that the closed loop has a
+// positive number of outstanding transactions and a positive timeout, and that
+// the lag threshold is valid.
+func validateLoad(c *configs.BenchConfig) (bool, error) {
+	load := c.TxInfo.Load
+
+	switch load.Mode {
+	case "", configs.LoadOpen:
+	case configs.LoadClosed:
+		if load.Outstanding <= 0 {
+			return false, fmt.Errorf("[%s] closed loop requires a positive number of outstanding transactions", c.Name)
+		}
+		if load.ThinkTime < 0 {
+			return false, fmt.Errorf("[%s] think time cannot be negative", c.Name)
+		}
+		if c.Timeout <= 0 {
+			return false, fmt.Errorf("[%s] closed loop requires a positive timeout", c.Name)
+		}
+	default:
+		return false, fmt.Errorf("[%s] unknown load mode \"%s\"", c.Name, load.Mode)
+	}
+
+	if c.TxInfo.LagThreshold < 0 {
+		return false, fmt.Errorf("[%s] lag threshold cannot be negative", c.Name)
+	}
+
+	return true, nil
+}
+
+// validateCompletion checks that the completion criteria are within range
+func validateCompletion(c *configs.BenchConfig) (bool, error) {
+	completion := c.Completion
+
+	if completion.CommittedFraction < 0 || completion.CommittedFraction > 1 {
+		return false, fmt.Errorf("[%s] completion committed fraction must be between 0 and 1", c.Name)
+	}
+
+	if completion.Idle < 0 {
+		return false, fmt.Errorf("[%s] completion idle period cannot be negative", c.Name)
+	}
+
+	if completion.Deadline < 0 {
+		return false, fmt.Errorf("[%s] completion deadline cannot be negative", c.Name)
+	}
+
+	return true, nil
+}
+
+// validateGeneration checks the mode of generation of the workload
+func validateGeneration(c *configs.BenchConfig) (bool, error) {
+	switch c.TxInfo.Generation.Mode {
+	case "", configs.GenerationPrimary, configs.GenerationSecondary:
+	default:
+		return false, fmt.Errorf("[%s] unknown generation mode \"%s\"", c.Name, c.TxInfo.Generation.Mode)
+	}
+
+	return true, nil
+}
+
+// validateResults checks the format of the results returned by the secondaries
+func validateResults(c *configs.BenchConfig) (bool, error) {
+	switch c.Results.Latencies {
+	case "", configs.LatencyFormatRaw, configs.LatencyFormatHistogram:
+	default:
+		return false, fmt.Errorf("[%s] unknown latency format \"%s\"", c.Name, c.Results.Latencies)
+	}
+
+	if c.Results.ThroughputWindow < 0 {
+		return false, fmt.Errorf("[%s] throughput window must not be negative", c.Name)
+	}
+
 	return true, nil
 }
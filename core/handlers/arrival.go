@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/configs"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// arrivalScheduler determines the offset of each transaction from the start
+// of its interval, according to the arrival process and pacing. Each worker
+// has its own scheduler so that the random arrivals are reproducible.
+type arrivalScheduler struct {
+	pacing  configs.PacingConfig  // Pacing used by the constant process
+	arrival configs.ArrivalConfig // Arrival process
+	rng     *rand.Rand            // Seeded source of the random arrivals
+}
+
+// newArrivalScheduler returns a scheduler whose random arrivals are drawn from the given seed
+func newArrivalScheduler(pacing configs.PacingConfig, arrival configs.ArrivalConfig, seed int64) *arrivalScheduler {
+	return &arrivalScheduler{
+		pacing:  pacing,
+		arrival: arrival,
+		rng:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// offsets returns the send offsets of the n transactions of the given interval
+func (s *arrivalScheduler) offsets(interval int, n int) []time.Duration {
+	switch s.arrival.Process {
+	case configs.ArrivalPoisson:
+		return s.poissonOffsets(n)
+	case configs.ArrivalBursty:
+		return s.burstyOffsets(interval, n)
+	default:
+		return getSendOffsets(s.pacing, n)
+	}
+}
+
+// poissonOffsets draws the arrivals of a Poisson process conditioned on n
+// arrivals in the interval, i.e. the sorted n uniform points of the interval.
+// This keeps the configured rate of each interval while the gaps between
+// arrivals are exponentially distributed.
+func (s *arrivalScheduler) poissonOffsets(n int) []time.Duration {
+	offsets := make([]time.Duration, n)
+	for i := range offsets {
+		offsets[i] = time.Duration(s.rng.Int63n(int64(intervalDuration)))
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
+}
+
+// burstyOffsets spreads the n transactions evenly over the on periods of the
+// interval. The on/off cycle is aligned on the start of the benchmark so that
+// it carries over from one interval to the next. If the interval has no on
+// period, the transactions are sent in a burst at its start.
+func (s *arrivalScheduler) burstyOffsets(interval int, n int) []time.Duration {
+	offsets := make([]time.Duration, n)
+
+	on := time.Duration(s.arrival.On) * time.Millisecond
+	cycle := on + time.Duration(s.arrival.Off)*time.Millisecond
+	intervalStart := time.Duration(interval) * intervalDuration
+
+	// Collect the on periods that overlap the interval, relative to its start
+	var periods [][2]time.Duration
+	var onTime time.Duration
+	for c := intervalStart - intervalStart%cycle; c < intervalStart+intervalDuration; c += cycle {
+		from, to := c-intervalStart, c+on-intervalStart
+		if from < 0 {
+			from = 0
+		}
+		if to > intervalDuration {
+			to = intervalDuration
+		}
+		if to > from {
+			periods = append(periods, [2]time.Duration{from, to})
+			onTime += to - from
+		}
+	}
+
+	if onTime == 0 {
+		return offsets
+	}
+
+	// Place transaction i at i/n of the on time, then map it back onto the interval
+	for i := range offsets {
+		position := time.Duration(int64(i) * int64(onTime) / int64(n))
+		for _, p := range periods {
+			if position < p[1]-p[0] {
+				offsets[i] = p[0] + position
+				break
+			}
+			position -= p[1] - p[0]
+		}
+	}
+
+	return offsets
+}
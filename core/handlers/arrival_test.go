@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/configs"
+	"testing"
+	"time"
+)
+
+func TestArrivalScheduler(t *testing.T) {
+	t.Run("poisson reproducible", func(t *testing.T) {
+		arrival := configs.ArrivalConfig{Process: configs.ArrivalPoisson}
+		a := newArrivalScheduler(configs.PacingConfig{}, arrival, 42).offsets(0, 100)
+		b := newArrivalScheduler(configs.PacingConfig{}, arrival, 42).offsets(0, 100)
+
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("poisson offset [%d]: same seed gave %v and %v", i, a[i], b[i])
+			}
+			if a[i] < 0 || a[i] >= intervalDuration {
+				t.Errorf("poisson offset [%d]: %v outside of the interval", i, a[i])
+			}
+			if i > 0 && a[i] < a[i-1] {
+				t.Errorf("poisson offset [%d]: offsets not sorted", i)
+			}
+		}
+	})
+
+	t.Run("bursty", func(t *testing.T) {
+		arrival := configs.ArrivalConfig{Process: configs.ArrivalBursty, On: 200, Off: 600}
+		s := newArrivalScheduler(configs.PacingConfig{}, arrival, 1)
+
+		// The second interval starts 200ms into an off period: on from 600ms to 800ms
+		offsets := s.offsets(1, 4)
+		expected := []time.Duration{600 * time.Millisecond, 650 * time.Millisecond, 700 * time.Millisecond, 750 * time.Millisecond}
+
+		for i := range expected {
+			if offsets[i] != expected[i] {
+				t.Errorf("bursty offset [%d]: expected %v, got %v", i, expected[i], offsets[i])
+			}
+		}
+	})
+}
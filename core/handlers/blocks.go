@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"diablo-benchmark/blockchains/clientinterfaces"
+	"diablo-benchmark/core/results"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// blockWalker walks the blocks produced by the chain during the benchmark, from
+// the height of the chain when the benchmark started
+type blockWalker struct {
+	client      clientinterfaces.BlockchainInterface // Client reading the blocks
+	startHeight uint64                               // Height of the chain when the benchmark started
+	started     bool                                 // Whether the start height is known
+}
+
+// start records the height of the chain when the benchmark starts
+func (w *blockWalker) start() {
+	height, err := w.client.GetBlockHeight()
+	if err != nil {
+		zap.L().Warn("failed to get the block height, the blocks will not be walked",
+			zap.Error(err))
+		return
+	}
+
+	w.startHeight = height
+	w.started = true
+}
+
+// walk returns the blocks produced since the benchmark started, those walked
+// before an error if the chain cannot return all of them
+func (w *blockWalker) walk() []results.BlockRecord {
+	if !w.started {
+		return nil
+	}
+
+	endHeight, err := w.client.GetBlockHeight()
+	if err != nil {
+		zap.L().Warn("failed to get the block height, the blocks will not be walked",
+			zap.Error(err))
+		return nil
+	}
+
+	var blocks []results.BlockRecord
+	for i := w.startHeight + 1; i <= endHeight; i++ {
+		b, err := w.client.GetBlockByNumber(i)
+		if err != nil {
+			zap.L().Warn("failed to get a block, stopping the walk",
+				zap.Uint64("block", i),
+				zap.Error(err))
+			break
+		}
+
+		blocks = append(blocks, results.BlockRecord{
+			Number:       i,
+			Timestamp:    int64(b.Timestamp) * int64(time.Second),
+			Transactions: b.TransactionNumber,
+			GasUsed:      b.GasUsed,
+			GasLimit:     b.GasLimit,
+		})
+	}
+
+	zap.L().Info("Walked the blocks of the benchmark",
+		zap.Uint64("start", w.startHeight),
+		zap.Uint64("end", endHeight),
+		zap.Int("blocks", len(blocks)))
+
+	return blocks
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/configs"
+	"diablo-benchmark/core/results"
+	"time"
+)
+
+// completionTracker decides when the wait for the sent transactions is over,
+// according to the completion criteria of the benchmark and the timeout.
+type completionTracker struct {
+	criteria     configs.Completion // Completion criteria of the benchmark
+	timeout      time.Duration      // Timeout after sending completed
+	start        time.Time          // Start of the benchmark
+	sendingEnd   time.Time          // End of the sending
+	lastDone     uint64             // Number of done transactions at the last progress
+	lastProgress time.Time          // Last time a transaction was done
+}
+
+// newCompletionTracker starts tracking the wait phase at the end of the sending
+func newCompletionTracker(criteria configs.Completion, timeout time.Duration, start time.Time, sendingEnd time.Time) *completionTracker {
+	return &completionTracker{
+		criteria:     criteria,
+		timeout:      timeout,
+		start:        start,
+		sendingEnd:   sendingEnd,
+		lastProgress: sendingEnd,
+	}
+}
+
+// deadline returns the absolute deadline of the benchmark, zero if not set
+func deadline(criteria configs.Completion, start time.Time) time.Time {
+	if criteria.Deadline <= 0 {
+		return time.Time{}
+	}
+
+	return start.Add(time.Duration(criteria.Deadline) * time.Second)
+}
+
+// check returns the criterion that ends the wait given the number of
+// transactions sent, done (committed or failed) and failed at the given time,
+// false if the wait goes on.
+func (c *completionTracker) check(now time.Time, sent uint64, done uint64, failed uint64) (results.CompletionReason, bool) {
+	if done > c.lastDone {
+		c.lastDone = done
+		c.lastProgress = now
+	}
+
+	if sent == 0 {
+		return results.CompletionEmpty, true
+	}
+
+	if done >= sent {
+		return results.CompletionAllDone, true
+	}
+
+	// Only the successful commits count towards the committed fraction
+	committed := uint64(0)
+	if done > failed {
+		committed = done - failed
+	}
+	if c.criteria.CommittedFraction > 0 && float64(committed)/float64(sent) >= c.criteria.CommittedFraction {
+		return results.CompletionCommittedFraction, true
+	}
+
+	if c.criteria.Idle > 0 && now.Sub(c.lastProgress) >= time.Duration(c.criteria.Idle)*time.Second {
+		return results.CompletionIdle, true
+	}
+
+	if d := deadline(c.criteria, c.start); !d.IsZero() && !now.Before(d) {
+		return results.CompletionDeadline, true
+	}
+
+	if now.Sub(c.sendingEnd) >= c.timeout {
+		return results.CompletionTimeout, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/configs"
+	"diablo-benchmark/core/results"
+	"testing"
+	"time"
+)
+
+func TestCompletionTracker(t *testing.T) {
+	start := time.Now()
+	sendingEnd := start.Add(10 * time.Second)
+	timeout := 20 * time.Second
+
+	t.Run("empty run", func(t *testing.T) {
+		c := newCompletionTracker(configs.Completion{}, timeout, start, sendingEnd)
+		if reason, done := c.check(sendingEnd, 0, 0, 0); !done || reason != results.CompletionEmpty {
+			t.Errorf("expected %s, got %s (done: %v)", results.CompletionEmpty, reason, done)
+		}
+	})
+
+	t.Run("all done", func(t *testing.T) {
+		c := newCompletionTracker(configs.Completion{}, timeout, start, sendingEnd)
+		if reason, done := c.check(sendingEnd, 10, 10, 0); !done || reason != results.CompletionAllDone {
+			t.Errorf("expected %s, got %s (done: %v)", results.CompletionAllDone, reason, done)
+		}
+	})
+
+	t.Run("committed fraction", func(t *testing.T) {
+		c := newCompletionTracker(configs.Completion{CommittedFraction: 0.9}, timeout, start, sendingEnd)
+		if _, done := c.check(sendingEnd, 10, 8, 0); done {
+			t.Errorf("expected to wait at 80%%")
+		}
+		if reason, done := c.check(sendingEnd, 10, 9, 0); !done || reason != results.CompletionCommittedFraction {
+			t.Errorf("expected %s, got %s (done: %v)", results.CompletionCommittedFraction, reason, done)
+		}
+		if _, done := c.check(sendingEnd, 10, 9, 5); done {
+			t.Errorf("expected to wait with only 40%% committed, the rest failed")
+		}
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		c := newCompletionTracker(configs.Completion{Idle: 3}, timeout, start, sendingEnd)
+		c.check(sendingEnd.Add(time.Second), 10, 5, 0)
+		if _, done := c.check(sendingEnd.Add(3*time.Second), 10, 5, 0); done {
+			t.Errorf("expected to wait, last progress 2s ago")
+		}
+		if reason, done := c.check(sendingEnd.Add(4*time.Second), 10, 5, 0); !done || reason != results.CompletionIdle {
+			t.Errorf("expected %s, got %s (done: %v)", results.CompletionIdle, reason, done)
+		}
+	})
+
+	t.Run("deadline and timeout", func(t *testing.T) {
+		c := newCompletionTracker(configs.Completion{Deadline: 15}, timeout, start, sendingEnd)
+		if reason, done := c.check(start.Add(15*time.Second), 10, 5, 0); !done || reason != results.CompletionDeadline {
+			t.Errorf("expected %s, got %s (done: %v)", results.CompletionDeadline, reason, done)
+		}
+
+		c = newCompletionTracker(configs.Completion{}, timeout, start, sendingEnd)
+		if reason, done := c.check(sendingEnd.Add(timeout), 10, 5, 0); !done || reason != results.CompletionTimeout {
+			t.Errorf("expected %s, got %s (done: %v)", results.CompletionTimeout, reason, done)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/configs"
+	"time"
+)
+
+// intervalDuration is the length of each interval of the workload
+const intervalDuration = time.Second
+
+// getSendOffsets returns the offset from the start of the interval at which
+// each of the n transactions of the interval should be sent, according to the
+// pacing mode.
+func getSendOffsets(pacing configs.PacingConfig, n int) []time.Duration {
+	offsets := make([]time.Duration, n)
+
+	switch pacing.Mode {
+	case configs.PacingEven:
+		// Transaction i is sent at i/n of the interval
+		for i := range offsets {
+			offsets[i] = time.Duration(int64(i) * int64(intervalDuration) / int64(n))
+		}
+	case configs.PacingStep:
+		// Split the interval into steps, the transactions are spread evenly
+		// over the steps and each step is sent in a burst at its start.
+		step := time.Duration(pacing.Granularity) * time.Millisecond
+		numSteps := int64((intervalDuration + step - 1) / step)
+		for i := range offsets {
+			offsets[i] = time.Duration(int64(i)*numSteps/int64(n)) * step
+		}
+	default:
+		// Burst: everything is sent at the start of the interval
+	}
+
+	return offsets
+}
+
+// waitUntil sleeps until the given time, returns immediately if it has passed.
+// Returns false if the stop channel was closed while waiting.
+func waitUntil(t time.Time, stop <-chan struct{}) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/configs"
+	"testing"
+	"time"
+)
+
+func TestGetSendOffsets(t *testing.T) {
+	t.Run("burst", func(t *testing.T) {
+		for i, v := range getSendOffsets(configs.PacingConfig{}, 10) {
+			if v != 0 {
+				t.Errorf("burst offset [%d]: expected 0, got %v", i, v)
+			}
+		}
+	})
+
+	t.Run("even", func(t *testing.T) {
+		offsets := getSendOffsets(configs.PacingConfig{Mode: configs.PacingEven}, 4)
+		expected := []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond}
+
+		for i := range expected {
+			if offsets[i] != expected[i] {
+				t.Errorf("even offset [%d]: expected %v, got %v", i, expected[i], offsets[i])
+			}
+		}
+	})
+
+	t.Run("step", func(t *testing.T) {
+		offsets := getSendOffsets(configs.PacingConfig{Mode: configs.PacingStep, Granularity: 500}, 4)
+		expected := []time.Duration{0, 0, 500 * time.Millisecond, 500 * time.Millisecond}
+
+		for i := range expected {
+			if offsets[i] != expected[i] {
+				t.Errorf("step offset [%d]: expected %v, got %v", i, expected[i], offsets[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/results"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// progressPeriod is the time between two progress reports
+const progressPeriod = 5 * time.Second
+
+// progressReporter tracks the progress of the workload against its schedule.
+// The consumers record each send, the reporter periodically logs the progress.
+type progressReporter struct {
+	targets      []uint64  // Number of transactions of each interval, across all workers
+	intervalSent []uint64  // Number of transactions sent in each interval, updated atomically
+	maxLag       int64     // Largest lag behind the schedule since the last report (ns), updated atomically
+	total        uint64    // Number of transactions of the workload
+	lastReport   time.Time // Time of the last report
+	lastDone     uint64    // Number of done transactions at the last report
+}
+
+// progressSnapshot is the progress of the workload at a point in time
+type progressSnapshot struct {
+	Interval  int           // Current interval of the schedule
+	Target    uint64        // Number of transactions of the current interval
+	Sent      uint64        // Number of transactions of the current interval sent
+	TotalSent uint64        // Number of transactions sent
+	Committed uint64        // Number of transactions done (committed or failed)
+	Errors    uint64        // Number of transactions that failed to send
+	Backlog   uint64        // Transactions of the elapsed intervals not sent yet
+	Pending   uint64        // Transactions sent and not done yet
+	Lag       time.Duration // Largest lag of the sends behind the schedule since the last report
+	Rate      float64       // Rate of done transactions since the last report (tx/s)
+	ETA       time.Duration // Estimated time to completion, negative if unknown
+}
+
+// newProgressReporter creates the reporter of the workload with the number of
+// transactions of each interval across the workers
+func newProgressReporter(targets []uint64) *progressReporter {
+	total := uint64(0)
+	for _, v := range targets {
+		total += v
+	}
+
+	return &progressReporter{
+		targets:      targets,
+		intervalSent: make([]uint64, len(targets)),
+		total:        total,
+	}
+}
+
+// addTargets adds the number of transactions of each interval of a worker
+func addTargets(targets []uint64, sizes []int) []uint64 {
+	for i, v := range sizes {
+		if i >= len(targets) {
+			targets = append(targets, 0)
+		}
+		targets[i] += uint64(v)
+	}
+
+	return targets
+}
+
+// recordSend records the send of a transaction, called by the consumers
+func (p *progressReporter) recordSend(record results.TransactionRecord) {
+	if record.Interval >= 0 && record.Interval < len(p.intervalSent) {
+		atomic.AddUint64(&p.intervalSent[record.Interval], 1)
+	}
+
+	lag := record.Sent - record.Scheduled
+	for {
+		current := atomic.LoadInt64(&p.maxLag)
+		if lag <= current || atomic.CompareAndSwapInt64(&p.maxLag, current, lag) {
+			return
+		}
+	}
+}
+
+// backlog returns the number of transactions of the intervals before the
+// given one that are not sent yet, as they should all have been sent
+func (p *progressReporter) backlog(interval int, sent uint64) uint64 {
+	elapsedTarget := uint64(0)
+	for i := 0; i < interval && i < len(p.targets); i++ {
+		elapsedTarget += p.targets[i]
+	}
+	if elapsedTarget > sent {
+		return elapsedTarget - sent
+	}
+
+	return 0
+}
+
+// snapshot computes the progress at the given time from the start of the
+// benchmark and the number of transactions sent, done and failed.
+func (p *progressReporter) snapshot(start time.Time, now time.Time, sent uint64, done uint64, errors uint64) progressSnapshot {
+	s := progressSnapshot{
+		Interval:  int(now.Sub(start) / intervalDuration),
+		TotalSent: sent,
+		Committed: done,
+		Errors:    errors,
+		Lag:       time.Duration(atomic.SwapInt64(&p.maxLag, 0)),
+		ETA:       -1,
+	}
+
+	s.Backlog = p.backlog(s.Interval, sent)
+
+	if s.Interval < len(p.targets) {
+		s.Target = p.targets[s.Interval]
+		s.Sent = atomic.LoadUint64(&p.intervalSent[s.Interval])
+	}
+
+	if sent > done {
+		s.Pending = sent - done
+	}
+
+	if p.lastReport.IsZero() {
+		p.lastReport = start
+	}
+	if elapsed := now.Sub(p.lastReport).Seconds(); elapsed > 0 && done >= p.lastDone {
+		s.Rate = float64(done-p.lastDone) / elapsed
+	}
+	p.lastReport = now
+	p.lastDone = done
+
+	// The workload cannot complete before the end of its schedule, and then
+	// completes at the current rate
+	if done >= p.total {
+		s.ETA = 0
+	} else if s.Rate > 0 {
+		s.ETA = time.Duration(float64(p.total-done) / s.Rate * float64(time.Second))
+		if remaining := start.Add(time.Duration(len(p.targets)) * intervalDuration).Sub(now); remaining > s.ETA {
+			s.ETA = remaining
+		}
+	}
+
+	return s
+}
+
+// run logs the progress periodically until stopped
+func (p *progressReporter) run(wh *WorkloadHandler, start time.Time, stop <-chan struct{}) {
+	ticker := time.NewTicker(progressPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s := p.snapshot(
+				start,
+				now,
+				atomic.LoadUint64(&wh.numTx),
+				wh.getTxCheck(),
+				atomic.LoadUint64(&wh.numErrors),
+			)
+
+			eta := "unknown"
+			if s.ETA >= 0 {
+				eta = s.ETA.Round(time.Second).String()
+			}
+
+			zap.L().Info("Progress",
+				zap.Int("interval", s.Interval),
+				zap.Uint64("target", s.Target),
+				zap.Uint64("sent", s.Sent),
+				zap.Uint64("totalSent", s.TotalSent),
+				zap.Uint64("committed", s.Committed),
+				zap.Uint64("errors", s.Errors),
+				zap.Uint64("backlog", s.Backlog),
+				zap.Uint64("pending", s.Pending),
+				zap.Duration("lag", s.Lag),
+				zap.Float64("rate", s.Rate),
+				zap.String("eta", eta))
+
+			if s.Backlog > 0 {
+				zap.L().Warn("Sending is behind the schedule",
+					zap.Uint64("backlog", s.Backlog),
+					zap.Duration("lag", s.Lag))
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/results"
+	"testing"
+	"time"
+)
+
+func TestProgressReporter(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("targets across workers", func(t *testing.T) {
+		targets := addTargets(nil, []int{2, 3})
+		targets = addTargets(targets, []int{1, 1, 4})
+
+		expected := []uint64{3, 4, 4}
+		for i, v := range expected {
+			if targets[i] != v {
+				t.Errorf("interval %d: expected target %d, got %d", i, v, targets[i])
+			}
+		}
+	})
+
+	t.Run("backlog, lag, rate and eta", func(t *testing.T) {
+		p := newProgressReporter([]uint64{10, 10, 10, 10})
+
+		// 12 sent in the first two intervals, the last one 300ms late
+		for i := 0; i < 12; i++ {
+			interval := i / 10
+			scheduled := start.Add(time.Duration(interval) * time.Second)
+			sent := scheduled
+			if i == 11 {
+				sent = scheduled.Add(300 * time.Millisecond)
+			}
+			p.recordSend(results.TransactionRecord{
+				Interval:  interval,
+				Scheduled: scheduled.UnixNano(),
+				Sent:      sent.UnixNano(),
+			})
+		}
+
+		s := p.snapshot(start, start.Add(2500*time.Millisecond), 12, 5, 1)
+
+		if s.Interval != 2 || s.Target != 10 || s.Sent != 0 {
+			t.Errorf("expected interval 2 with 0/10 sent, got interval %d with %d/%d", s.Interval, s.Sent, s.Target)
+		}
+		if s.Backlog != 8 {
+			t.Errorf("expected a backlog of 8, got %d", s.Backlog)
+		}
+		if s.Pending != 7 {
+			t.Errorf("expected 7 pending, got %d", s.Pending)
+		}
+		if s.Lag != 300*time.Millisecond {
+			t.Errorf("expected a lag of 300ms, got %v", s.Lag)
+		}
+		if s.Rate != 2 {
+			t.Errorf("expected a rate of 2 tx/s, got %.3f", s.Rate)
+		}
+		// 35 remaining at 2 tx/s
+		if s.ETA != 17500*time.Millisecond {
+			t.Errorf("expected an ETA of 17.5s, got %v", s.ETA)
+		}
+
+		// The lag is reset and nothing was done since
+		s = p.snapshot(start, start.Add(3*time.Second), 12, 5, 1)
+		if s.Lag != 0 {
+			t.Errorf("expected the lag to be reset, got %v", s.Lag)
+		}
+		if s.ETA >= 0 {
+			t.Errorf("expected an unknown ETA without progress, got %v", s.ETA)
+		}
+	})
+
+	t.Run("eta is bounded by the schedule", func(t *testing.T) {
+		p := newProgressReporter([]uint64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
+
+		s := p.snapshot(start, start.Add(time.Second), 1, 1, 0)
+		if s.ETA != 9*time.Second {
+			t.Errorf("expected an ETA of 9s, got %v", s.ETA)
+		}
+	})
+}
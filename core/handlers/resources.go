@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"diablo-benchmark/core/results"
+	"errors"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// resourceSampleInterval is the interval between the samples of the resources of the secondary
+const resourceSampleInterval = time.Second
+
+// clockTicks is the number of clock ticks per second of the CPU times in /proc (USER_HZ)
+const clockTicks = 100
+
+// resourceMonitor samples the resources used by the secondary process from
+// /proc and the Go runtime while the benchmark runs
+type resourceMonitor struct {
+	samples   []results.ResourceSample // Samples of the resources
+	stopCh    chan struct{}            // Closed to stop sampling
+	done      chan struct{}            // Closed when sampling stopped
+	lastTime  time.Time                // Time of the previous sample
+	lastCPU   uint64                   // CPU time of the process at the previous sample [ticks]
+	lastPause uint64                   // Total GC pause at the previous sample [ns]
+	lastRx    uint64                   // Bytes received at the previous sample
+	lastTx    uint64                   // Bytes sent at the previous sample
+}
+
+// newResourceMonitor returns a monitor with the current counters as reference
+func newResourceMonitor() *resourceMonitor {
+	m := &resourceMonitor{
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	var err error
+	m.lastTime = time.Now()
+	if m.lastCPU, err = processCPU(); err != nil {
+		zap.L().Warn("failed to read the CPU time of the process, it will not be monitored",
+			zap.Error(err))
+	}
+	if m.lastRx, m.lastTx, err = networkBytes(); err != nil {
+		zap.L().Warn("failed to read the network statistics, they will not be monitored",
+			zap.Error(err))
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	m.lastPause = memStats.PauseTotalNs
+
+	return m
+}
+
+// run samples the resources at the given interval until stopped
+func (m *resourceMonitor) run(interval time.Duration) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			m.sample()
+			return
+		case <-ticker.C:
+			m.sample()
+		}
+	}
+}
+
+// stop stops sampling, after a last sample
+func (m *resourceMonitor) stop() {
+	close(m.stopCh)
+	<-m.done
+}
+
+// usage returns the resources used while sampling, must be called once stopped
+func (m *resourceMonitor) usage() results.ResourceUsage {
+	return results.NewResourceUsage(runtime.NumCPU(), m.samples)
+}
+
+// sample samples the resources used since the previous sample. The counters
+// that cannot be read are left at 0.
+func (m *resourceMonitor) sample() {
+	now := time.Now()
+	s := results.ResourceSample{
+		Time:       now.UnixNano(),
+		Goroutines: runtime.NumGoroutine(),
+	}
+
+	if cpu, err := processCPU(); err == nil {
+		if elapsed := now.Sub(m.lastTime).Seconds(); elapsed > 0 && cpu >= m.lastCPU {
+			s.CPU = 100 * float64(cpu-m.lastCPU) / clockTicks / elapsed
+		}
+		m.lastCPU = cpu
+	}
+
+	if rss, err := processRSS(); err == nil {
+		s.RSS = rss
+	}
+
+	if rx, tx, err := networkBytes(); err == nil {
+		if rx >= m.lastRx && tx >= m.lastTx {
+			s.NetRx, s.NetTx = rx-m.lastRx, tx-m.lastTx
+		}
+		m.lastRx, m.lastTx = rx, tx
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	s.HeapAlloc = memStats.HeapAlloc
+	s.GCPause = float64(memStats.PauseTotalNs-m.lastPause) / float64(time.Millisecond)
+	m.lastPause = memStats.PauseTotalNs
+
+	m.lastTime = now
+	m.samples = append(m.samples, s)
+}
+
+// processCPU returns the CPU time (user and system) used by the process [ticks]
+func processCPU() (uint64, error) {
+	content, err := ioutil.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, err
+	}
+
+	return parseProcStat(string(content))
+}
+
+// parseProcStat returns the user and system CPU time of the content of /proc/<pid>/stat [ticks]
+func parseProcStat(content string) (uint64, error) {
+	// The command name is in parentheses and may contain spaces
+	end := strings.LastIndex(content, ")")
+	if end < 0 {
+		return 0, errors.New("malformed stat: no command name")
+	}
+
+	// Fields after the command name, starting with the state
+	fields := strings.Fields(content[end+1:])
+	if len(fields) < 13 {
+		return 0, errors.New("malformed stat: missing the CPU times")
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return utime + stime, nil
+}
+
+// processRSS returns the resident memory of the process [bytes]
+func processRSS() (uint64, error) {
+	content, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) < 2 {
+		return 0, errors.New("malformed statm: missing the resident pages")
+	}
+
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return pages * uint64(os.Getpagesize()), nil
+}
+
+// networkBytes returns the bytes received and sent on the network interfaces
+// of the process, except the loopback
+func networkBytes() (uint64, uint64, error) {
+	content, err := ioutil.ReadFile("/proc/self/net/dev")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return parseNetDev(string(content))
+}
+
+// parseNetDev returns the bytes received and sent of the content of /proc/net/dev,
+// except on the loopback interface
+func parseNetDev(content string) (uint64, uint64, error) {
+	var rx, tx uint64
+
+	lines := strings.Split(content, "\n")
+	if len(lines) < 2 {
+		return 0, 0, errors.New("malformed net/dev: missing the header")
+	}
+
+	// The first two lines are the header
+	for _, line := range lines[2:] {
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		if strings.TrimSpace(line[:colon]) == "lo" {
+			continue
+		}
+
+		// Received bytes is the first field, sent bytes the ninth
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) < 9 {
+			return 0, 0, errors.New("malformed net/dev: missing the byte counters")
+		}
+
+		r, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		t, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		rx += r
+		tx += t
+	}
+
+	return rx, tx, nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcParsing(t *testing.T) {
+	t.Run("stat", func(t *testing.T) {
+		// The command name contains a space and a parenthesis
+		content := "1234 (diablo (x) y) S 1 1234 1234 0 -1 4194560 2000 0 0 0 150 25 0 0 20 0 12 0 100 0 0\n"
+		ticks, err := parseProcStat(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ticks != 175 {
+			t.Errorf("expected 175 ticks, got %d", ticks)
+		}
+
+		if _, err := parseProcStat("1234 (diablo) S 1"); err == nil {
+			t.Error("expected an error for a truncated stat")
+		}
+	})
+
+	t.Run("net/dev", func(t *testing.T) {
+		content := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  500000     100    0    0    0     0          0         0   500000     100    0    0    0     0       0          0
+  eth0:    1000      10    0    0    0     0          0         0     2000      20    0    0    0     0       0          0
+  eth1:     300       3    0    0    0     0          0         0      400       4    0    0    0     0       0          0
+`
+		rx, tx, err := parseNetDev(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rx != 1300 || tx != 2400 {
+			t.Errorf("expected 1300 bytes received and 2400 sent, got %d and %d", rx, tx)
+		}
+	})
+}
+
+func TestResourceMonitor(t *testing.T) {
+	m := newResourceMonitor()
+	go m.run(10 * time.Millisecond)
+	time.Sleep(35 * time.Millisecond)
+	m.stop()
+
+	usage := m.usage()
+	if len(usage.Samples) < 2 || usage.NumCPU == 0 || usage.MaxGoroutines == 0 {
+		t.Errorf("unexpected resource usage %+v", usage)
+	}
+}
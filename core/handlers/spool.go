@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"bufio"
+	"diablo-benchmark/blockchains/clientinterfaces"
+	"diablo-benchmark/blockchains/workloadgenerators"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// spoolLookahead is the number of intervals decoded ahead of the producer when
+// the workload is spooled, bounding the memory to a few seconds of load.
+const spoolLookahead = 2
+
+// workloadSpool is the workload of a worker spooled to a file, with one
+// segment per interval. Each segment is the number of transactions followed by
+// the length-prefixed transactions.
+type workloadSpool struct {
+	path  string // Path of the spool file
+	sizes []int  // Number of transactions in each interval
+	err   error  // Error that stopped decoding the spool, set before the intervals are closed
+}
+
+// spoolWriter writes the intervals of the workload of a worker to its spool file
+type spoolWriter struct {
+	spool  *workloadSpool // Spool being written
+	f      *os.File       // Spool file
+	w      *bufio.Writer  // Buffered writer of the spool file
+	header []byte         // Buffer of the length prefixes
+}
+
+// newSpoolWriter creates the spool file of a worker in the directory
+func newSpoolWriter(dir string, id int) (*spoolWriter, error) {
+	f, err := ioutil.TempFile(dir, fmt.Sprintf("worker%d-*.spool", id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &spoolWriter{
+		spool:  &workloadSpool{path: f.Name()},
+		f:      f,
+		w:      bufio.NewWriter(f),
+		header: make([]byte, 4),
+	}, nil
+}
+
+// writeInterval appends the transactions of the next interval to the spool
+func (sw *spoolWriter) writeInterval(interval [][]byte) error {
+	binary.BigEndian.PutUint32(sw.header, uint32(len(interval)))
+	if _, err := sw.w.Write(sw.header); err != nil {
+		return err
+	}
+
+	for _, tx := range interval {
+		binary.BigEndian.PutUint32(sw.header, uint32(len(tx)))
+		if _, err := sw.w.Write(sw.header); err != nil {
+			return err
+		}
+		if _, err := sw.w.Write(tx); err != nil {
+			return err
+		}
+	}
+
+	sw.spool.sizes = append(sw.spool.sizes, len(interval))
+	return nil
+}
+
+// close flushes and closes the spool file, removing it on failure
+func (sw *spoolWriter) close() error {
+	err := sw.w.Flush()
+	if closeErr := sw.f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(sw.spool.path)
+	}
+
+	return err
+}
+
+// spoolWorkload writes the workload of a worker into a new file of the directory
+func spoolWorkload(dir string, id int, workload workloadgenerators.WorkerThreadWorkload) (*workloadSpool, error) {
+	sw, err := newSpoolWriter(dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, interval := range workload {
+		if err = sw.writeInterval(interval); err != nil {
+			sw.close()
+			os.Remove(sw.spool.path)
+			return nil, err
+		}
+	}
+
+	if err = sw.close(); err != nil {
+		return nil, err
+	}
+
+	return sw.spool, nil
+}
+
+// spoolWorkloadStream spools the workload of each worker of the secondary
+// from its JSON encoding (as sent by the primary) into the directory, decoding
+// one interval at a time so that the whole workload is never held in memory.
+func spoolWorkloadStream(dir string, r io.Reader) ([]*workloadSpool, error) {
+	var spools []*workloadSpool
+	removeAll := func() {
+		for _, v := range spools {
+			os.Remove(v.path)
+		}
+	}
+
+	dec := json.NewDecoder(r)
+	if _, err := openArray(dec); err != nil {
+		return nil, err
+	}
+
+	for dec.More() {
+		sw, err := newSpoolWriter(dir, len(spools))
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		if err = spoolWorkerStream(dec, sw); err != nil {
+			sw.close()
+			os.Remove(sw.spool.path)
+			removeAll()
+			return nil, err
+		}
+
+		if err = sw.close(); err != nil {
+			removeAll()
+			return nil, err
+		}
+		spools = append(spools, sw.spool)
+	}
+
+	if err := closeArray(dec); err != nil {
+		removeAll()
+		return nil, err
+	}
+
+	return spools, nil
+}
+
+// spoolWorkerStream spools the intervals of the workload of a worker
+func spoolWorkerStream(dec *json.Decoder, sw *spoolWriter) error {
+	// The workload of a worker without transactions may be null
+	isArray, err := openArray(dec)
+	if err != nil || !isArray {
+		return err
+	}
+
+	for dec.More() {
+		var interval [][]byte
+		if err = dec.Decode(&interval); err != nil {
+			return err
+		}
+		if err = sw.writeInterval(interval); err != nil {
+			return err
+		}
+	}
+
+	return closeArray(dec)
+}
+
+// openArray reads the start of a JSON array, returns false if the value is null
+func openArray(dec *json.Decoder) (bool, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if token == nil {
+		return false, nil
+	}
+	if token != json.Delim('[') {
+		return false, fmt.Errorf("malformed workload: expected an array, got %v", token)
+	}
+
+	return true, nil
+}
+
+// closeArray reads the end of a JSON array
+func closeArray(dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim(']') {
+		return fmt.Errorf("malformed workload: expected the end of an array, got %v", token)
+	}
+
+	return nil
+}
+
+// maxIntervalSize returns the number of transactions of the largest interval
+func (s *workloadSpool) maxIntervalSize() int {
+	largest := 0
+	for _, v := range s.sizes {
+		if v > largest {
+			largest = v
+		}
+	}
+
+	return largest
+}
+
+// totalSize returns the number of transactions of the workload
+func (s *workloadSpool) totalSize() int {
+	total := 0
+	for _, v := range s.sizes {
+		total += v
+	}
+
+	return total
+}
+
+// readSegment reads the transactions of the next interval of the spool
+func readSegment(r io.Reader) (workloadgenerators.WorkerThreadWorkload, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	interval := make([][]byte, binary.BigEndian.Uint32(header))
+	for i := range interval {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+
+		interval[i] = make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(r, interval[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return workloadgenerators.WorkerThreadWorkload{interval}, nil
+}
+
+// decode reads the intervals of the spool and parses them with the client
+// just in time, keeping at most spoolLookahead intervals ahead of the producer.
+// The spool file is removed once read, or if stopped. An error reading or
+// parsing the spool stops the intervals and is kept in err.
+func (s *workloadSpool) decode(client clientinterfaces.BlockchainInterface, stop <-chan struct{}) <-chan []interface{} {
+	intervals := make(chan []interface{}, spoolLookahead)
+
+	go func() {
+		defer close(intervals)
+		defer os.Remove(s.path)
+
+		f, err := os.Open(s.path)
+		if err != nil {
+			zap.L().Error("failed to open workload spool",
+				zap.String("path", s.path),
+				zap.Error(err))
+			s.err = err
+			return
+		}
+		defer f.Close()
+
+		r := bufio.NewReader(f)
+		for range s.sizes {
+			segment, err := readSegment(r)
+			if err != nil {
+				zap.L().Error("failed to read workload spool",
+					zap.String("path", s.path),
+					zap.Error(err))
+				s.err = err
+				return
+			}
+
+			parsed, err := client.ParseWorkload(segment)
+			if err != nil {
+				zap.L().Error("failed to parse spooled interval",
+					zap.String("path", s.path),
+					zap.Error(err))
+				s.err = err
+				return
+			}
+
+			select {
+			case intervals <- parsed[0]:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return intervals
+}
+
+// memoryIntervals provides the intervals of a workload held in memory in the
+// same way as a spooled workload is decoded.
+func memoryIntervals(workload [][]interface{}) <-chan []interface{} {
+	intervals := make(chan []interface{}, len(workload))
+	for _, v := range workload {
+		intervals <- v
+	}
+	close(intervals)
+
+	return intervals
+}
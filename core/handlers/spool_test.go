@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"diablo-benchmark/blockchains/workloadgenerators"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWorkloadSpool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diablo-spool")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	workload := workloadgenerators.WorkerThreadWorkload{
+		{[]byte("a"), []byte("bb")},
+		{},
+		{[]byte("ccc"), []byte("d"), []byte("ee")},
+	}
+
+	spool, err := spoolWorkload(dir, 0, workload)
+	if err != nil {
+		t.Fatalf("failed to spool workload: %s", err)
+	}
+
+	if spool.totalSize() != 5 || spool.maxIntervalSize() != 3 {
+		t.Errorf("unexpected sizes: %v", spool.sizes)
+	}
+
+	var decoded [][]interface{}
+	for interval := range spool.decode(&fakeClient{}, make(chan struct{})) {
+		decoded = append(decoded, interval)
+	}
+
+	if len(decoded) != len(workload) {
+		t.Fatalf("expected %d intervals, got %d", len(workload), len(decoded))
+	}
+
+	for i := range workload {
+		if len(decoded[i]) != len(workload[i]) {
+			t.Fatalf("interval %d: expected %d transactions, got %d", i, len(workload[i]), len(decoded[i]))
+		}
+		for j := range workload[i] {
+			if string(decoded[i][j].([]byte)) != string(workload[i][j]) {
+				t.Errorf("interval %d tx %d: expected %s, got %s", i, j, workload[i][j], decoded[i][j])
+			}
+		}
+	}
+
+	if _, err := os.Stat(spool.path); !os.IsNotExist(err) {
+		t.Errorf("expected the spool file to be removed once read")
+	}
+}
+
+func TestWorkloadSpoolStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diablo-spool")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	workload := workloadgenerators.SecondaryWorkload{
+		{{[]byte("a"), []byte("bb")}, {}},
+		nil,
+		{{[]byte("ccc")}},
+	}
+
+	encoded, err := json.Marshal(workload)
+	if err != nil {
+		t.Fatalf("failed to encode workload: %s", err)
+	}
+
+	t.Run("intervals are spooled per worker", func(t *testing.T) {
+		spools, err := spoolWorkloadStream(dir, bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("failed to spool workload: %s", err)
+		}
+
+		if len(spools) != len(workload) {
+			t.Fatalf("expected %d spools, got %d", len(workload), len(spools))
+		}
+
+		for i, spool := range spools {
+			var decoded [][]interface{}
+			for interval := range spool.decode(&fakeClient{}, make(chan struct{})) {
+				decoded = append(decoded, interval)
+			}
+
+			if spool.err != nil {
+				t.Fatalf("worker %d: unexpected error: %s", i, spool.err)
+			}
+			if len(decoded) != len(workload[i]) {
+				t.Fatalf("worker %d: expected %d intervals, got %d", i, len(workload[i]), len(decoded))
+			}
+			for j := range workload[i] {
+				for k := range workload[i][j] {
+					if string(decoded[j][k].([]byte)) != string(workload[i][j][k]) {
+						t.Errorf("worker %d interval %d tx %d: expected %s, got %s", i, j, k, workload[i][j][k], decoded[j][k])
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("truncated workload is rejected", func(t *testing.T) {
+		if _, err := spoolWorkloadStream(dir, bytes.NewReader(encoded[:len(encoded)/2])); err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		files, _ := ioutil.ReadDir(dir)
+		if len(files) != 0 {
+			t.Errorf("expected the spool files to be removed, found %d", len(files))
+		}
+	})
+
+	t.Run("read errors are kept", func(t *testing.T) {
+		spools, err := spoolWorkloadStream(dir, bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("failed to spool workload: %s", err)
+		}
+
+		if err = os.Truncate(spools[0].path, 3); err != nil {
+			t.Fatalf("failed to truncate spool: %s", err)
+		}
+		for range spools[0].decode(&fakeClient{}, make(chan struct{})) {
+		}
+
+		if spools[0].err == nil {
+			t.Errorf("expected the read error to be kept")
+		}
+		for _, spool := range spools[1:] {
+			os.Remove(spool.path)
+		}
+	})
+}
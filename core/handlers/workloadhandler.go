@@ -8,6 +8,8 @@ import (
 	"diablo-benchmark/core/results"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -28,24 +30,78 @@ type WorkloadHandler struct {
 	numErrors            uint64                                 // Number of errors during workload
 	StartEnd             []time.Time                            // Start and end of the benchmark
 	timeout              int                                    // Timeout to wait for the benchmark
+	pacing               configs.PacingConfig                   // Pacing of the transactions within each interval
+	arrival              configs.ArrivalConfig                  // Arrival process of the transactions within each interval
+	load                 configs.LoadConfig                     // Open or closed loop load
+	secondaryID          int                                    // ID of the secondary, used to seed the arrivals of each worker
+	txRecords            [][]results.TransactionRecord          // Record of the transactions sent by each worker
+	completion           configs.Completion                     // Criteria ending the benchmark before the timeout
+	stopCh               chan struct{}                          // Closed to stop the workers sending (deadline reached)
+	CompletionReason     results.CompletionReason               // Criterion that ended the benchmark
+	spoolDir             string                                 // Directory the workload is spooled to, empty to keep it in memory
+	progress             *progressReporter                      // Progress of the workload against its schedule
+	latencyFormat        configs.LatencyFormat                  // Format of the latencies returned in the results
+	observer             clientinterfaces.TransactionObserver   // Observer of the completed transactions, nil if none
+	startTime            int64                                  // Start of the benchmark (unix ns), updated atomically
+	blocks               *blockWalker                           // Walker of the blocks of the benchmark, nil if this secondary does not walk them
+	resources            *resourceMonitor                       // Monitor of the resources used by the secondary during the benchmark
+	spools               []*workloadSpool                       // Spools of the workloads of the workers, nil if kept in memory
+	sendErrors           []map[results.ErrorClass]uint          // Number of transactions of each worker that failed to send per class, when records are not kept
+}
+
+// Stats are the live counters of the workload, safe to read while the benchmark runs
+type Stats struct {
+	Sent    uint64 // Transactions sent
+	Done    uint64 // Transactions done (committed or failed)
+	Errors  uint64 // Transactions that failed to send
+	Backlog uint64 // Transactions of the elapsed intervals not sent yet
 }
 
 // NewWorkloadHandler provides a new workload handler with number of threads and clients
-func NewWorkloadHandler(numThread uint32, clients []clientinterfaces.BlockchainInterface, timeout int) *WorkloadHandler {
+func NewWorkloadHandler(numThread uint32, clients []clientinterfaces.BlockchainInterface, benchConfig *configs.BenchConfig) *WorkloadHandler {
 	// Generate the channels to speak to the workers.
 	return &WorkloadHandler{
 		numThread:     numThread,
 		activeClients: clients,
-		timeout:       timeout,
+		timeout:       benchConfig.Timeout,
+		pacing:        benchConfig.TxInfo.Pacing,
+		arrival:       benchConfig.TxInfo.Arrival,
+		load:          benchConfig.TxInfo.Load,
+		completion:    benchConfig.Completion,
+		latencyFormat: benchConfig.Results.Latencies,
+	}
+}
+
+// SetObserver sets the observer notified of the completed transactions of all
+// the clients and of the transactions that failed to send
+func (wh *WorkloadHandler) SetObserver(observer clientinterfaces.TransactionObserver) {
+	wh.observer = observer
+	for _, c := range wh.activeClients {
+		c.SetObserver(observer)
 	}
 }
 
+// compact returns true if only the latency histograms and error counts of the
+// transactions are kept, so that the memory does not grow with the benchmark
+func (wh *WorkloadHandler) compact() bool {
+	return wh.latencyFormat == configs.LatencyFormatHistogram
+}
+
+// SetSpoolDir spools the workloads to files in the given directory rather than
+// keeping them in memory, decoding each interval just in time.
+func (wh *WorkloadHandler) SetSpoolDir(dir string) {
+	wh.spoolDir = dir
+}
+
 // Connect initialises the clients and connects to the nodes
 func (wh *WorkloadHandler) Connect(chainConfig *configs.ChainConfig, ID int) error {
+	wh.secondaryID = ID
+
 	var combinedErr []string
 	for _, v := range wh.activeClients {
 		v.Init(chainConfig)
 		v.SetWindow(chainConfig.ThroughputWindow)
+		v.SetCompact(wh.compact())
 		e := v.ConnectAll(ID)
 		if e != nil {
 			combinedErr = append(combinedErr, e.Error())
@@ -59,117 +115,358 @@ func (wh *WorkloadHandler) Connect(chainConfig *configs.ChainConfig, ID int) err
 	return nil
 }
 
+// workerSource provides the parsed intervals of the workload of a worker
+type workerSource struct {
+	intervals   <-chan []interface{} // Parsed intervals of the workload, in order
+	sizes       []int                // Number of transactions in each interval
+	channelSize int                  // Transactions buffered between the producer and the consumer
+}
+
+// scheduledTx is a transaction passed from the producer to the consumer, with
+// its record holding the schedule for the consumer to complete
+type scheduledTx struct {
+	tx     interface{}               // Transaction parsed by the client
+	record results.TransactionRecord // Record of the transaction
+}
+
 // ParseWorkloads parse the workloads on each client, populate the channels
 func (wh *WorkloadHandler) ParseWorkloads(rawWorkload workloadgenerators.SecondaryWorkload) error {
-
-	// Set up the workload channels
-	var readyChannels []chan bool
-	var wg sync.WaitGroup
+	wh.stopCh = make(chan struct{})
+	wh.spools = nil
 
 	var fullWorkload [][][]interface{}
-
+	var sources []workerSource
 	for i, workerWorkload := range rawWorkload {
-		// Should be able to parse the workloads from transactions into bytes
-		parsedWorkerWorkload, err := wh.activeClients[0].ParseWorkload(workerWorkload)
+		if wh.spoolDir != "" {
+			// Spool the workload to disk, the intervals are decoded just in
+			// time so that only a few seconds of load are held in memory
+			spool, err := spoolWorkload(wh.spoolDir, i, workerWorkload)
+			if err != nil {
+				return err
+			}
+
+			sources = append(sources, wh.spoolSource(i, spool))
+			continue
+		}
+
+		// Should be able to parse the workloads from transactions into bytes,
+		// each worker parses with its own client so that it can track them
+		parsedWorkerWorkload, err := wh.activeClients[i].ParseWorkload(workerWorkload)
 		if err != nil {
 			return err
 		}
 
-		channelSize := 0
-		for _, v := range parsedWorkerWorkload {
-			channelSize += len(v)
+		source := workerSource{
+			intervals: memoryIntervals(parsedWorkerWorkload),
+			sizes:     make([]int, len(parsedWorkerWorkload)),
+		}
+		for k, v := range parsedWorkerWorkload {
+			source.sizes[k] = len(v)
+			source.channelSize += len(v)
+		}
+
+		sources = append(sources, source)
+		fullWorkload = append(fullWorkload, parsedWorkerWorkload)
+	}
+
+	wh.FullWorkload = fullWorkload
+	wh.startWorkers(sources)
+	return nil
+}
+
+// SpoolWorkloads spools the workloads of the workers from their encoding sent
+// by the primary, one interval at a time, and populates the channels. The
+// spool directory must be set.
+func (wh *WorkloadHandler) SpoolWorkloads(r io.Reader) error {
+	if wh.spoolDir == "" {
+		return errors.New("no spool directory to spool the workload to")
+	}
+
+	wh.stopCh = make(chan struct{})
+	wh.spools = nil
+
+	spools, err := spoolWorkloadStream(wh.spoolDir, r)
+	if err != nil {
+		return err
+	}
+
+	if len(spools) > len(wh.activeClients) {
+		for _, v := range spools {
+			os.Remove(v.path)
+		}
+		return fmt.Errorf("workload for %d workers sent to %d workers", len(spools), len(wh.activeClients))
+	}
+
+	var sources []workerSource
+	for i, spool := range spools {
+		sources = append(sources, wh.spoolSource(i, spool))
+	}
+
+	wh.FullWorkload = nil
+	wh.startWorkers(sources)
+	return nil
+}
+
+// spoolSource returns the source of the intervals of a worker decoded from its spool
+func (wh *WorkloadHandler) spoolSource(id int, spool *workloadSpool) workerSource {
+	wh.spools = append(wh.spools, spool)
+
+	return workerSource{
+		intervals:   spool.decode(wh.activeClients[id], wh.stopCh),
+		sizes:       spool.sizes,
+		channelSize: spool.maxIntervalSize() * spoolLookahead,
+	}
+}
+
+// spoolError returns the first error that stopped decoding a spool, once the
+// producers are done
+func (wh *WorkloadHandler) spoolError() error {
+	for _, v := range wh.spools {
+		if v.err != nil {
+			return v.err
 		}
+	}
+
+	return nil
+}
+
+// startWorkers starts the producer and consumer of each worker on the intervals of its source
+func (wh *WorkloadHandler) startWorkers(sources []workerSource) {
+	// Set up the workload channels
+	var readyChannels []chan bool
+	var wg sync.WaitGroup
+	var targets []uint64
+
+	wh.txRecords = make([][]results.TransactionRecord, len(sources))
+	wh.sendErrors = make([]map[results.ErrorClass]uint, len(sources))
+
+	// A zero seed picks a random one, logged so that the run can be reproduced
+	seed := wh.arrival.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if wh.arrival.Process == configs.ArrivalPoisson {
+		zap.L().Info("Poisson arrivals",
+			zap.Int64("seed", seed))
+	}
+
+	for i, source := range sources {
+		targets = addTargets(targets, source.sizes)
 
 		readyChannel := make(chan bool, 0)
 		readyChannels = append(readyChannels, readyChannel)
 
-		workerChannel := make(chan interface{}, channelSize)
+		// The producer fills the schedule of each record before passing the
+		// transaction on, the consumer then stamps its send time and keeps it
+		workerChannel := make(chan scheduledTx, source.channelSize)
 		wg.Add(1)
 		// Make my consumer
-		go wh.runnerConsumer(
-			wh.activeClients[i],
-			workerChannel,
-			&wg,
-		)
+		if wh.load.Mode == configs.LoadClosed {
+			go wh.closedLoopConsumer(
+				wh.activeClients[i],
+				workerChannel,
+				i,
+				&wg,
+			)
+		} else {
+			go wh.runnerConsumer(
+				wh.activeClients[i],
+				workerChannel,
+				i,
+				&wg,
+			)
+		}
 
 		// Start the worker producer
 		go wh.workloadProducer(
-			parsedWorkerWorkload,
+			source.intervals,
 			workerChannel,
 			readyChannel,
-			i,
+			newArrivalScheduler(wh.pacing, wh.arrival, seed+int64(wh.secondaryID)*int64(wh.numThread)+int64(i)),
 		)
-
-		fullWorkload = append(fullWorkload, parsedWorkerWorkload)
 	}
 
-	wh.FullWorkload = fullWorkload
+	wh.progress = newProgressReporter(targets)
 	wh.readyChannels = readyChannels
 	wh.wg = &wg
-	return nil
 }
 
-// workloadProducer producer that places transactions into the queue and handles naive rate limiting.
-func (wh *WorkloadHandler) workloadProducer(workload [][]interface{}, workerChan chan interface{}, ready chan bool, id int) {
-	zap.L().Debug(fmt.Sprintf("producer %d ready", id))
-	<-ready
-	currentIterator := 1
-	for _, v := range workload[0] {
-		workerChan <- v
-	}
+// workloadProducer producer that places transactions into the queue at the
+// send times given by the arrival scheduler, recording the intended send time
+// of each transaction.
+func (wh *WorkloadHandler) workloadProducer(intervals <-chan []interface{}, workerChan chan scheduledTx, ready chan bool, scheduler *arrivalScheduler) {
+	defer close(workerChan)
 
-	// Set up the timer
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+	<-ready
+	start := time.Now()
+
+	// Transactions are scheduled against the start of the benchmark rather
+	// than the previous send, so that the rate catches up if sending lags.
+	interval := 0
+	for intervalWorkload := range intervals {
+		intervalStart := start.Add(time.Duration(interval) * intervalDuration)
+		offsets := scheduler.offsets(interval, len(intervalWorkload))
+
+		for i, v := range intervalWorkload {
+			stx := scheduledTx{
+				tx:     v,
+				record: results.TransactionRecord{Interval: interval},
+			}
 
-	for {
-		select {
-		case <-ticker.C:
-			for _, v := range workload[currentIterator] {
-				workerChan <- v
+			// In closed loop the sends are driven by the completions, the
+			// consumer schedules the transaction once a slot is free
+			if wh.load.Mode != configs.LoadClosed {
+				scheduled := intervalStart.Add(offsets[i])
+				if !waitUntil(scheduled, wh.stopCh) {
+					// Deadline reached, the remaining transactions are not sent
+					return
+				}
+				stx.record.Scheduled = scheduled.UnixNano()
 			}
-			currentIterator++
-			if currentIterator >= len(workload) {
-				close(workerChan)
+
+			select {
+			case workerChan <- stx:
+			case <-wh.stopCh:
 				return
 			}
 		}
-	}
 
+		interval++
+	}
 }
 
 // runnerConsumer consumer that runs the workload pulling from the channel
-func (wh *WorkloadHandler) runnerConsumer(blockchainInterface clientinterfaces.BlockchainInterface, workload chan interface{}, wg *sync.WaitGroup) {
-	var errs []error
+func (wh *WorkloadHandler) runnerConsumer(blockchainInterface clientinterfaces.BlockchainInterface, workload chan scheduledTx, id int, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	// Wait for the signal to go
-	for tx := range workload {
-		e := blockchainInterface.SendRawTransaction(tx)
-		if e != nil {
-			zap.L().Debug("Error sending tx",
-				zap.Error(e))
-			errs = append(errs, e)
-			atomic.AddUint64(&wh.numErrors, 1)
+	for stx := range workload {
+		if wh.stopped() {
+			return
 		}
-		atomic.AddUint64(&wh.numTx, 1)
+
+		wh.send(blockchainInterface, stx, id)
 	}
+}
 
-	// TODO handle errors
+// send sends the transaction with the client of the worker and keeps its
+// record, returns false if it failed to send
+func (wh *WorkloadHandler) send(blockchainInterface clientinterfaces.BlockchainInterface, stx scheduledTx, id int) bool {
+	record := stx.record
+	record.Sent = time.Now().UnixNano()
+
+	e := blockchainInterface.SendRawTransaction(stx.tx)
+	if e != nil {
+		zap.L().Debug("Error sending tx",
+			zap.Error(e))
+		record.Error = blockchainInterface.ClassifyError(e)
+		atomic.AddUint64(&wh.numErrors, 1)
+		if wh.observer != nil {
+			wh.observer.ObserveFailure(record.Error)
+		}
+	}
+	atomic.AddUint64(&wh.numTx, 1)
+	wh.progress.recordSend(record)
+	if !wh.compact() {
+		wh.txRecords[id] = append(wh.txRecords[id], record)
+	} else if record.Error != "" {
+		if wh.sendErrors[id] == nil {
+			wh.sendErrors[id] = make(map[results.ErrorClass]uint)
+		}
+		wh.sendErrors[id][record.Error]++
+	}
+
+	return e == nil
 }
 
-// statusPrinter periodically prints the status of the workload progress
-func (wh *WorkloadHandler) statusPrinter(stopCh chan bool) {
-	timer := time.NewTicker(5 * time.Second)
-	for {
+// closedLoopConsumer consumer that keeps at most the configured number of
+// transactions outstanding, sending the next transaction only once a previous
+// one was committed or failed (and the think time elapsed).
+func (wh *WorkloadHandler) closedLoopConsumer(blockchainInterface clientinterfaces.BlockchainInterface, workload chan scheduledTx, id int, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Each outstanding transaction holds a slot until it completes
+	slots := make(chan struct{}, wh.load.Outstanding)
+	thinkTime := time.Duration(wh.load.ThinkTime) * time.Millisecond
+
+	// Outstanding transactions that expired after the timeout, their slot was reused
+	var expiredLock sync.Mutex
+	expired := 0
+
+	blockchainInterface.SetCompletionHandler(func(success bool) {
+		// The completion of an expired transaction frees no slot
+		expiredLock.Lock()
+		if expired > 0 {
+			expired--
+			expiredLock.Unlock()
+			return
+		}
+		expiredLock.Unlock()
+
+		release := func() {
+			select {
+			case <-slots:
+			default:
+			}
+		}
+
+		if thinkTime > 0 {
+			time.AfterFunc(thinkTime, release)
+		} else {
+			release()
+		}
+	})
+
+	// Do not stall forever on transactions that never complete
+	stallTimeout := time.Duration(wh.timeout) * time.Second
+	if stallTimeout <= 0 {
+		stallTimeout = time.Duration(configs.DefaultTimeout) * time.Second
+	}
+	stall := time.NewTimer(stallTimeout)
+	defer stall.Stop()
+
+	for stx := range workload {
+		if !stall.Stop() {
+			select {
+			case <-stall.C:
+			default:
+			}
+		}
+		stall.Reset(stallTimeout)
+
 		select {
-		case <-stopCh:
-			timer.Stop()
+		case slots <- struct{}{}:
+		case <-wh.stopCh:
 			return
-		case <-timer.C:
-			// print
-			zap.L().Info(fmt.Sprintf("SENT: %d tx (%d errors)", wh.numTx, wh.numErrors))
+		case <-stall.C:
+			// Expire an outstanding transaction and reuse its slot
+			zap.L().Warn("no transaction completed within the timeout, expiring an outstanding transaction",
+				zap.Int("outstanding", wh.load.Outstanding))
+			expiredLock.Lock()
+			expired++
+			expiredLock.Unlock()
 		}
+
+		// The transaction is scheduled once it holds a slot, so that the
+		// wait for the slot is not counted as lagging behind the schedule
+		stx.record.Scheduled = time.Now().UnixNano()
+		if !wh.send(blockchainInterface, stx, id) {
+			// The transaction is not outstanding, free its slot
+			select {
+			case <-slots:
+			default:
+			}
+		}
+	}
+}
+
+// stopped returns true once the workers have been told to stop sending
+func (wh *WorkloadHandler) stopped() bool {
+	select {
+	case <-wh.stopCh:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -182,49 +479,77 @@ func (wh *WorkloadHandler) getTxCheck() uint64 {
 	return fullTx
 }
 
+// getTxFailed returns the number of completed transactions that failed
+func (wh *WorkloadHandler) getTxFailed() uint64 {
+	failed := uint64(0)
+	for _, v := range wh.activeClients {
+		failed += v.GetTxFailed()
+	}
+
+	return failed
+}
+
 // RunBench executes the benchmark
 func (wh *WorkloadHandler) RunBench() error {
 	wh.StartEnd = append(wh.StartEnd, time.Now())
-	stopPrinting := make(chan bool, 0)
+	atomic.StoreInt64(&wh.startTime, wh.StartEnd[0].UnixNano())
+	stopProgress := make(chan struct{})
+
+	go wh.progress.run(wh, wh.StartEnd[0], stopProgress)
 
-	go wh.statusPrinter(stopPrinting)
+	wh.resources = newResourceMonitor()
+	go wh.resources.run(resourceSampleInterval)
+
+	// The first secondary walks the blocks produced during the benchmark
+	if wh.secondaryID == 0 && len(wh.activeClients) > 0 {
+		wh.blocks = &blockWalker{client: wh.activeClients[0]}
+		wh.blocks.start()
+	}
 
 	for i, ch := range wh.readyChannels {
 		wh.activeClients[i].Start()
 		ch <- true
 	}
 
-	// All of the threads have stopped sending, we should wait some time for
-	// confirmations
-	wh.wg.Wait()
+	// All of the threads have stopped sending, unless the deadline is reached
+	// first, in which case the workers are stopped.
+	sendingDone := make(chan struct{})
+	go func() {
+		wh.wg.Wait()
+		close(sendingDone)
+	}()
+
+	var deadlineC <-chan time.Time
+	if d := deadline(wh.completion, wh.StartEnd[0]); !d.IsZero() {
+		deadlineTimer := time.NewTimer(time.Until(d))
+		defer deadlineTimer.Stop()
+		deadlineC = deadlineTimer.C
+	}
 
-	// Sending finished waiting for timeout
-	// TODO: add configurable timeout that will exit if benchmark not complete
+	select {
+	case <-sendingDone:
+	case <-deadlineC:
+		zap.L().Warn("Deadline reached while sending, stopping the workers")
+		close(wh.stopCh)
+		<-sendingDone
+	}
+
+	// Sending finished waiting for the completion criteria
 	zap.L().Info("Sending complete, waiting for finish")
-	stopPrinting <- true
 
-	waitingTicker := time.NewTicker(1 * time.Second)
-	waitCount := 0
-	td := uint64(0)
-	for {
-		select {
-		case <-waitingTicker.C:
-			waitCount++
-			td = wh.getTxCheck()
-			zap.L().Debug("TX Done:",
-				zap.Uint64("tx", td),
-				zap.Uint64("total", wh.numTx),
-			)
-			break
-		}
-		if waitCount >= wh.timeout || (td/wh.numTx) == 1 {
-			break
-		}
+	wh.CompletionReason = wh.waitForCompletion()
+	if err := wh.spoolError(); err != nil {
+		zap.L().Error("failed to read the spooled workload, the rest of the workload was not sent",
+			zap.Error(err))
+		wh.CompletionReason = results.CompletionSpoolError
 	}
+	close(stopProgress)
+	wh.resources.stop()
 
 	wh.StartEnd = append(wh.StartEnd, time.Now())
 
 	zap.L().Info("Benchmark complete:",
+		zap.String("reason", string(wh.CompletionReason)),
 		zap.Time("start", wh.StartEnd[0]),
 		zap.Time("end", wh.StartEnd[1]),
 		zap.Duration("duration", wh.StartEnd[1].Sub(wh.StartEnd[0])))
@@ -233,23 +558,133 @@ func (wh *WorkloadHandler) RunBench() error {
 	return nil
 }
 
+// Stats returns the live counters of the workload, the workloads must have been parsed
+func (wh *WorkloadHandler) Stats() Stats {
+	stats := Stats{
+		Sent:   atomic.LoadUint64(&wh.numTx),
+		Done:   wh.getTxCheck(),
+		Errors: atomic.LoadUint64(&wh.numErrors),
+	}
+
+	if start := atomic.LoadInt64(&wh.startTime); start > 0 && wh.progress != nil {
+		interval := int(time.Since(time.Unix(0, start)) / intervalDuration)
+		stats.Backlog = wh.progress.backlog(interval, stats.Sent)
+	}
+
+	return stats
+}
+
+// waitForCompletion waits for the sent transactions to be done until one of
+// the completion criteria is met, returning the criterion.
+func (wh *WorkloadHandler) waitForCompletion() results.CompletionReason {
+	tracker := newCompletionTracker(wh.completion, time.Duration(wh.timeout)*time.Second, wh.StartEnd[0], time.Now())
+
+	waitingTicker := time.NewTicker(1 * time.Second)
+	defer waitingTicker.Stop()
+
+	for {
+		td := wh.getTxCheck()
+		sent := atomic.LoadUint64(&wh.numTx)
+		zap.L().Debug("TX Done:",
+			zap.Uint64("tx", td),
+			zap.Uint64("total", sent),
+		)
+
+		if reason, done := tracker.check(time.Now(), sent, td, wh.getTxFailed()); done {
+			return reason
+		}
+
+		<-waitingTicker.C
+	}
+}
+
 // HandleCleanup performs all post-benchmark calculation and returns the result set
 func (wh *WorkloadHandler) HandleCleanup() []results.Results {
 
+	host, err := os.Hostname()
+	if err != nil {
+		zap.L().Warn("failed to get the host name",
+			zap.Error(err))
+	}
+
+	var blocks []results.BlockRecord
+	if wh.blocks != nil {
+		blocks = wh.blocks.walk()
+	}
+
 	var resList []results.Results
-	for _, c := range wh.activeClients {
-		resList = append(resList, c.Cleanup())
+	for i, c := range wh.activeClients {
+		res := c.Cleanup()
+		if i == 0 {
+			res.Blocks = blocks
+			if wh.resources != nil {
+				usage := wh.resources.usage()
+				res.Resources = &usage
+			}
+		}
+		res.CompletionReason = wh.CompletionReason
+		res.Host = host
+		if wh.compact() {
+			// The client counts the errors of the transactions it sent
+			if i < len(wh.sendErrors) {
+				for class, count := range wh.sendErrors[i] {
+					if res.Errors == nil {
+						res.Errors = make(map[results.ErrorClass]uint)
+					}
+					res.Errors[class] += count
+				}
+			}
+		} else if i < len(wh.txRecords) {
+			// The client returns the commit times and errors in the order
+			// the transactions were sent, merge them into the worker's records
+			records := wh.txRecords[i]
+			for k := range records {
+				if k < len(res.Transactions) {
+					tx := res.Transactions[k]
+					records[k].ID = tx.ID
+					records[k].Function = tx.Function
+					records[k].Type = tx.Type
+					records[k].Committed = tx.Committed
+					records[k].Acknowledged = tx.Acknowledged
+					records[k].Included = tx.Included
+					records[k].Finalized = tx.Finalized
+					records[k].Block = tx.Block
+					if records[k].Error == "" {
+						records[k].Error = tx.Error
+					}
+				}
+			}
+			res.Transactions = records
+			res.Errors = results.CountErrors(records)
+		}
+
+		// In compact mode the client returns the histogram of the latencies
+		if res.Histogram == nil {
+			res.Histogram = results.NewLatencyHistogram(res.TxLatencies)
+		}
+		res.Percentiles = res.Histogram.Percentiles()
+		if wh.compact() {
+			// The histogram and the error counts replace the unbounded lists
+			// of latencies and transaction records
+			res.TxLatencies = nil
+			res.Transactions = nil
+		}
+		resList = append(resList, res)
 	}
 
 	zap.L().Debug("Results being returned",
 		zap.Int("len", len(resList)))
 
-	fmt.Println(resList)
 	return resList
 }
 
 // CloseAll closes the clients and the channels
 func (wh *WorkloadHandler) CloseAll() {
+	// Stop any routine still decoding a spooled workload
+	if wh.stopCh != nil && !wh.stopped() {
+		close(wh.stopCh)
+	}
+
 	for _, c := range wh.activeClients {
 		c.Close()
 	}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"diablo-benchmark/blockchains/clientinterfaces"
+	"diablo-benchmark/blockchains/workloadgenerators"
+	"diablo-benchmark/core/configs"
+	"diablo-benchmark/core/results"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient completes every transaction shortly after it is sent, unless
+// stalled, and tracks the maximum number of outstanding transactions.
+type fakeClient struct {
+	mu             sync.Mutex
+	stalled        bool
+	outstanding    int
+	maxOutstanding int
+	handler        func(success bool)
+	clientinterfaces.GenericInterface
+}
+
+func (f *fakeClient) SetCompletionHandler(handler func(success bool)) { f.handler = handler }
+
+func (f *fakeClient) SendRawTransaction(tx interface{}) error {
+	f.mu.Lock()
+	f.outstanding++
+	if f.outstanding > f.maxOutstanding {
+		f.maxOutstanding = f.outstanding
+	}
+	f.mu.Unlock()
+
+	if f.stalled {
+		return nil
+	}
+
+	time.AfterFunc(time.Millisecond, func() {
+		f.mu.Lock()
+		f.outstanding--
+		f.mu.Unlock()
+		atomic.AddUint64(&f.NumTxDone, 1)
+		f.handler(true)
+	})
+
+	return nil
+}
+
+func (f *fakeClient) ClassifyError(err error) results.ErrorClass { return results.ErrorOther }
+
+func (f *fakeClient) Init(chainConfig *configs.ChainConfig) {}
+func (f *fakeClient) Cleanup() results.Results              { return results.Results{} }
+func (f *fakeClient) Start()                                {}
+func (f *fakeClient) ConnectOne(id int) error               { return nil }
+func (f *fakeClient) ConnectAll(primaryID int) error        { return nil }
+func (f *fakeClient) Close()                                {}
+func (f *fakeClient) GetBlockHeight() (uint64, error)       { return 0, nil }
+
+func (f *fakeClient) ParseWorkload(workload workloadgenerators.WorkerThreadWorkload) ([][]interface{}, error) {
+	parsed := make([][]interface{}, len(workload))
+	for i, interval := range workload {
+		for _, tx := range interval {
+			parsed[i] = append(parsed[i], tx)
+		}
+	}
+	return parsed, nil
+}
+
+func (f *fakeClient) DeploySmartContract(tx interface{}) (interface{}, error) { return nil, nil }
+
+func (f *fakeClient) SecureRead(callFunc string, callParams []byte) (interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) GetBlockByNumber(index uint64) (clientinterfaces.GenericBlock, error) {
+	return clientinterfaces.GenericBlock{}, nil
+}
+
+func (f *fakeClient) ParseBlocksForTransactions(startNumber uint64, endNumber uint64) error {
+	return nil
+}
+
+func TestClosedLoop(t *testing.T) {
+	client := &fakeClient{}
+	benchConfig := &configs.BenchConfig{
+		Timeout: 5,
+		TxInfo: configs.BenchInfo{
+			Load: configs.LoadConfig{Mode: configs.LoadClosed, Outstanding: 3},
+		},
+	}
+
+	workload := make(workloadgenerators.WorkerThreadWorkload, 1)
+	for i := 0; i < 50; i++ {
+		workload[0] = append(workload[0], []byte{byte(i)})
+	}
+
+	wh := NewWorkloadHandler(1, []clientinterfaces.BlockchainInterface{client}, benchConfig)
+	if err := wh.ParseWorkloads(workloadgenerators.SecondaryWorkload{workload}); err != nil {
+		t.Fatalf("failed to parse workload: %s", err)
+	}
+
+	if err := wh.RunBench(); err != nil {
+		t.Fatalf("failed to run benchmark: %s", err)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.maxOutstanding > 3 {
+		t.Errorf("expected at most 3 outstanding transactions, got %d", client.maxOutstanding)
+	}
+	if wh.numTx != 50 {
+		t.Errorf("expected 50 transactions sent, got %d", wh.numTx)
+	}
+}
+
+func TestClosedLoopStalled(t *testing.T) {
+	client := &fakeClient{stalled: true}
+	benchConfig := &configs.BenchConfig{
+		Timeout: 1,
+		TxInfo: configs.BenchInfo{
+			Load: configs.LoadConfig{Mode: configs.LoadClosed, Outstanding: 2},
+		},
+	}
+
+	workload := make(workloadgenerators.WorkerThreadWorkload, 1)
+	for i := 0; i < 4; i++ {
+		workload[0] = append(workload[0], []byte{byte(i)})
+	}
+
+	wh := NewWorkloadHandler(1, []clientinterfaces.BlockchainInterface{client}, benchConfig)
+	if err := wh.ParseWorkloads(workloadgenerators.SecondaryWorkload{workload}); err != nil {
+		t.Fatalf("failed to parse workload: %s", err)
+	}
+
+	start := time.Now()
+	if err := wh.RunBench(); err != nil {
+		t.Fatalf("failed to run benchmark: %s", err)
+	}
+
+	// The two transactions beyond the outstanding ones each waited for the timeout
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Errorf("expected the worker to wait for the timeout before expiring, took %v", elapsed)
+	}
+	if wh.numTx != 4 {
+		t.Errorf("expected 4 transactions sent, got %d", wh.numTx)
+	}
+}
@@ -0,0 +1,226 @@
+// Package hooks provides the lifecycle hooks that are run between the phases
+// of the benchmark. Hooks can be shell commands defined in the benchmark
+// configuration, or in-process Go hooks registered through RegisterHook. They
+// can be used to start resource monitors, snapshot node logs or restart nodes
+// between the phases of the benchmark. The output of every hook is captured in
+// the results directory.
+package hooks
+
+import (
+	"context"
+	"diablo-benchmark/core/configs"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Roles of the benchmark nodes that run the hooks
+const (
+	RolePrimary   = "primary"   // Hook is running on the primary
+	RoleSecondary = "secondary" // Hook is running on a secondary
+)
+
+// RunInfo describes the benchmark run to the hooks. It is passed to the Go
+// hooks and is exported as environment variables to the shell hooks.
+type RunInfo struct {
+	RunID           string   // Unique identifier of this run (timestamp of the start)
+	Role            string   // Role of the node running the hook (primary / secondary)
+	SecondaryID     int      // ID of the secondary (only set on the secondary)
+	BenchName       string   // Name of the benchmark
+	BenchConfigPath string   // Path of the benchmark configuration
+	ChainName       string   // Name of the blockchain
+	ChainConfigPath string   // Path of the chain configuration
+	Nodes           []string // Blockchain nodes
+	Secondaries     int      // Number of secondaries
+	Threads         int      // Number of threads per secondary
+	ResultsDir      string   // Directory where results and hook outputs are written
+}
+
+// Hook is an in-process hook that is run at every phase of the benchmark.
+// The returned output is captured in the results directory.
+type Hook interface {
+	// Run executes the hook for the given phase of the benchmark.
+	Run(phase configs.HookPhase, info RunInfo) ([]byte, error)
+}
+
+// registeredHook is an in-process Go hook registered through RegisterHook
+type registeredHook struct {
+	hook     Hook // Hook run at every phase
+	required bool // Whether the phase fails if the hook fails
+}
+
+var (
+	registeredHooks     = make(map[string]registeredHook)
+	registeredHooksLock sync.Mutex
+)
+
+// RegisterHook registers an in-process Go hook under the given name, it will
+// be run at every phase of the benchmark on both the primary and secondaries,
+// in the order of the names. If required, the phase fails when the hook fails.
+func RegisterHook(name string, hook Hook, required bool) {
+	registeredHooksLock.Lock()
+	defer registeredHooksLock.Unlock()
+	registeredHooks[name] = registeredHook{hook: hook, required: required}
+}
+
+// Runner runs the hooks defined in the benchmark configuration, as well as the
+// registered Go hooks, for one node of the benchmark.
+type Runner struct {
+	hookConfigs []configs.HookConfig // Hooks from the benchmark configuration that run on this node
+	info        RunInfo              // Information about the run passed to the hooks
+}
+
+// NewRunner returns a runner for the hooks that are defined to run on the given
+// role (primary or secondary).
+func NewRunner(hookConfigs []configs.HookConfig, info RunInfo) *Runner {
+	var roleHooks []configs.HookConfig
+
+	for _, h := range hookConfigs {
+		runOn := h.RunOn
+		if runOn == "" {
+			runOn = configs.HookOnPrimary
+		}
+
+		if runOn == configs.HookOnAll || runOn == info.Role {
+			roleHooks = append(roleHooks, h)
+		}
+	}
+
+	return &Runner{
+		hookConfigs: roleHooks,
+		info:        info,
+	}
+}
+
+// environment returns the environment variables describing the run for a phase
+func (r *Runner) environment(phase configs.HookPhase) []string {
+	return append(os.Environ(),
+		fmt.Sprintf("DIABLO_PHASE=%s", phase),
+		fmt.Sprintf("DIABLO_RUN_ID=%s", r.info.RunID),
+		fmt.Sprintf("DIABLO_ROLE=%s", r.info.Role),
+		fmt.Sprintf("DIABLO_SECONDARY_ID=%d", r.info.SecondaryID),
+		fmt.Sprintf("DIABLO_BENCH_NAME=%s", r.info.BenchName),
+		fmt.Sprintf("DIABLO_BENCH_CONFIG=%s", r.info.BenchConfigPath),
+		fmt.Sprintf("DIABLO_CHAIN_NAME=%s", r.info.ChainName),
+		fmt.Sprintf("DIABLO_CHAIN_CONFIG=%s", r.info.ChainConfigPath),
+		fmt.Sprintf("DIABLO_NODES=%s", strings.Join(r.info.Nodes, ",")),
+		fmt.Sprintf("DIABLO_SECONDARIES=%d", r.info.Secondaries),
+		fmt.Sprintf("DIABLO_THREADS=%d", r.info.Threads),
+		fmt.Sprintf("DIABLO_RESULTS_DIR=%s", r.info.ResultsDir),
+	)
+}
+
+// writeOutput captures the output of a hook in the hooks directory of the results
+func (r *Runner) writeOutput(phase configs.HookPhase, name string, output []byte) {
+	hookDir := filepath.Join(r.info.ResultsDir, "hooks")
+	err := os.MkdirAll(hookDir, 0755)
+	if err != nil {
+		zap.L().Warn("failed to create hook output directory",
+			zap.String("dir", hookDir),
+			zap.Error(err))
+		return
+	}
+
+	nodeName := r.info.Role
+	if r.info.Role == RoleSecondary {
+		nodeName = fmt.Sprintf("%s%d", r.info.Role, r.info.SecondaryID)
+	}
+
+	path := filepath.Join(hookDir, fmt.Sprintf("%s_%s_%s_%s.log", r.info.RunID, nodeName, phase, name))
+	err = ioutil.WriteFile(path, output, 0644)
+	if err != nil {
+		zap.L().Warn("failed to write hook output",
+			zap.String("path", path),
+			zap.Error(err))
+	}
+}
+
+// runCommand runs the shell command of the hook and returns the combined output
+func (r *Runner) runCommand(phase configs.HookPhase, h configs.HookConfig) ([]byte, error) {
+	ctx := context.Background()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(h.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Env = r.environment(phase)
+
+	return cmd.CombinedOutput()
+}
+
+// RunPhase runs all hooks for the given phase. Failing hooks are logged, an
+// error is only returned if a hook marked as required failed. A nil runner
+// (e.g. before the node is prepared) runs no hooks.
+func (r *Runner) RunPhase(phase configs.HookPhase) error {
+	if r == nil {
+		return nil
+	}
+
+	var requiredErrs []string
+
+	for i, h := range r.hookConfigs {
+		if h.Phase != phase {
+			continue
+		}
+
+		zap.L().Info("running hook",
+			zap.String("phase", string(phase)),
+			zap.String("command", h.Command))
+
+		output, err := r.runCommand(phase, h)
+		r.writeOutput(phase, fmt.Sprintf("hook%d", i), output)
+
+		if err != nil {
+			zap.L().Warn("hook failed",
+				zap.String("phase", string(phase)),
+				zap.String("command", h.Command),
+				zap.Error(err))
+			if h.Required {
+				requiredErrs = append(requiredErrs, fmt.Sprintf("%s: %s", h.Command, err.Error()))
+			}
+		}
+	}
+
+	registeredHooksLock.Lock()
+	defer registeredHooksLock.Unlock()
+
+	names := make([]string, 0, len(registeredHooks))
+	for name := range registeredHooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		h := registeredHooks[name]
+		output, err := h.hook.Run(phase, r.info)
+		if len(output) > 0 {
+			r.writeOutput(phase, name, output)
+		}
+
+		if err != nil {
+			zap.L().Warn("hook failed",
+				zap.String("phase", string(phase)),
+				zap.String("hook", name),
+				zap.Error(err))
+			if h.required {
+				requiredErrs = append(requiredErrs, fmt.Sprintf("%s: %s", name, err.Error()))
+			}
+		}
+	}
+
+	if len(requiredErrs) > 0 {
+		return fmt.Errorf("required hooks failed in phase %s: %s", phase, strings.Join(requiredErrs, ", "))
+	}
+
+	return nil
+}
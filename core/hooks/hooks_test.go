@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"diablo-benchmark/core/configs"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPhase(t *testing.T) {
+	resultsDir, err := ioutil.TempDir("", "diablo-hooks")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(resultsDir)
+
+	info := RunInfo{
+		RunID:      "test",
+		Role:       RolePrimary,
+		BenchName:  "sample",
+		ResultsDir: resultsDir,
+	}
+
+	t.Run("hook output captured", func(t *testing.T) {
+		r := NewRunner([]configs.HookConfig{
+			{Phase: configs.HookPhaseRunStart, Command: "echo $DIABLO_PHASE $DIABLO_BENCH_NAME"},
+			{Phase: configs.HookPhaseRunStart, Command: "echo secondary", RunOn: configs.HookOnSecondary},
+		}, info)
+
+		if err := r.RunPhase(configs.HookPhaseRunStart); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(resultsDir, "hooks", "*"))
+		if len(files) != 1 {
+			t.Fatalf("expected 1 hook output, got %d", len(files))
+		}
+
+		output, _ := ioutil.ReadFile(files[0])
+		if strings.TrimSpace(string(output)) != "run_start sample" {
+			t.Errorf("output mismatch: got %s", output)
+		}
+	})
+
+	t.Run("required hook fails", func(t *testing.T) {
+		r := NewRunner([]configs.HookConfig{
+			{Phase: configs.HookPhaseSetup, Command: "exit 1"},
+		}, info)
+
+		if err := r.RunPhase(configs.HookPhaseSetup); err != nil {
+			t.Errorf("expected optional hook failure to be ignored, got %s", err)
+		}
+
+		r = NewRunner([]configs.HookConfig{
+			{Phase: configs.HookPhaseSetup, Command: "exit 1", Required: true},
+		}, info)
+
+		if err := r.RunPhase(configs.HookPhaseSetup); err == nil {
+			t.Errorf("expected required hook to fail")
+		}
+	})
+	t.Run("go hooks in order", func(t *testing.T) {
+		var order []string
+		for _, name := range []string{"c", "a", "b"} {
+			name := name
+			RegisterHook(name, hookFunc(func(configs.HookPhase, RunInfo) ([]byte, error) {
+				order = append(order, name)
+				return nil, errors.New("failed")
+			}), name == "b")
+		}
+		defer func() {
+			registeredHooks = make(map[string]registeredHook)
+		}()
+
+		r := NewRunner(nil, info)
+		if err := r.RunPhase(configs.HookPhaseSetup); err == nil || !strings.Contains(err.Error(), "b: failed") {
+			t.Errorf("expected the required Go hook to fail the phase, got %v", err)
+		}
+		if strings.Join(order, "") != "abc" {
+			t.Errorf("expected the Go hooks to run in the order of their names, got %v", order)
+		}
+	})
+
+	t.Run("nil runner", func(t *testing.T) {
+		var r *Runner
+		if err := r.RunPhase(configs.HookPhaseSetup); err != nil {
+			t.Errorf("expected a nil runner to run no hooks, got %s", err)
+		}
+	})
+}
+
+// hookFunc is a Go hook defined by a function
+type hookFunc func(phase configs.HookPhase, info RunInfo) ([]byte, error)
+
+func (f hookFunc) Run(phase configs.HookPhase, info RunInfo) ([]byte, error) {
+	return f(phase, info)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"diablo-benchmark/blockchains/workloadgenerators"
+	"diablo-benchmark/core/configs"
+	"diablo-benchmark/core/results"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// MergeResults aggregates the results written locally by the secondaries of a
+// run into the results the primary would have produced, for runs where the
+// primary failed to collect them. The configurations are those of the run.
+func MergeResults(paths []string, bConfig *configs.BenchConfig, cConfig *configs.ChainConfig) (results.AggregatedResults, error) {
+	var secondaries []results.SecondaryResults
+	for _, path := range paths {
+		sr, err := results.LoadSecondaryResults(path)
+		if err != nil {
+			return results.AggregatedResults{}, err
+		}
+		secondaries = append(secondaries, sr)
+	}
+
+	rawResults, start, end, err := results.MergeSecondaryResults(secondaries)
+	if err != nil {
+		return results.AggregatedResults{}, err
+	}
+
+	if len(rawResults) != bConfig.Secondaries {
+		zap.L().Warn(fmt.Sprintf("Merging the results of %d secondaries out of %d", len(rawResults), bConfig.Secondaries))
+	}
+
+	generatorClass, err := workloadgenerators.GetWorkloadGenerator(cConfig)
+	if err != nil {
+		return results.AggregatedResults{}, err
+	}
+	functions := generatorClass.NewGenerator(cConfig, bConfig).ContractFunctions()
+
+	aggregatedResults := results.CalculateAggregatedResults(rawResults)
+
+	aggregatedResults.Metadata = results.NewMetadata(start.Format("20060102T150405"), start, end, bConfig.Path, cConfig.Path)
+	// No primary saw the addresses of the secondaries, only their hosts are known
+	aggregatedResults.Metadata.AddSecondaries(make([]string, len(rawResults)), rawResults)
+	// Keep the IDs of the secondaries, some may be missing
+	for i := range aggregatedResults.Metadata.Secondaries {
+		aggregatedResults.Metadata.Secondaries[i].ID = secondaries[i].SecondaryID
+	}
+
+	// The workload is not available, count the transactions the secondaries
+	// sent, from their records or else from their outcomes
+	var workloadTx uint
+	for _, secondaryResult := range rawResults {
+		for _, workerResult := range secondaryResult {
+			if len(workerResult.Transactions) > 0 {
+				workloadTx += uint(len(workerResult.Transactions))
+			} else {
+				workloadTx += workerResult.Success + workerResult.Fail
+			}
+		}
+	}
+
+	analyseResults(&aggregatedResults, bConfig, functions, workloadTx)
+
+	return aggregatedResults, nil
+}
@@ -0,0 +1,58 @@
+// Package metrics exposes the state of the benchmark as Prometheus metrics, so
+// that the load generated by Diablo can be monitored on the same dashboards as
+// the blockchain nodes. The primary and the secondaries each serve their own
+// metrics on an optional /metrics HTTP endpoint, built from the counters the
+// workload handler and the client interfaces already maintain.
+package metrics
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// namespace prefixes the names of all the metrics
+const namespace = "diablo"
+
+// Path is the HTTP path the metrics are served on
+const Path = "/metrics"
+
+// newRegistry creates a registry with the Go runtime and process metrics
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+
+	return registry
+}
+
+// serve serves the metrics of the registry on the given address in the
+// background. The address is listened on before returning so that an invalid
+// or busy address is reported to the caller.
+func serve(addr string, registry *prometheus.Registry) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			zap.L().Warn("metrics endpoint stopped",
+				zap.Error(err))
+		}
+	}()
+
+	zap.L().Info("Serving metrics",
+		zap.String("addr", listener.Addr().String()),
+		zap.String("path", Path))
+
+	return nil
+}
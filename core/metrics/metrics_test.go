@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"diablo-benchmark/core/handlers"
+	"diablo-benchmark/core/results"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeSource returns fixed workload counters
+type fakeSource struct {
+	stats handlers.Stats
+}
+
+func (f *fakeSource) Stats() handlers.Stats { return f.stats }
+
+func TestSecondaryMetrics(t *testing.T) {
+	t.Run("counters kept across benchmarks", func(t *testing.T) {
+		m := NewSecondaryMetrics()
+		m.SetSource(&fakeSource{handlers.Stats{Sent: 10, Done: 8, Backlog: 3}})
+		m.SetSource(&fakeSource{handlers.Stats{Sent: 5, Done: 1, Backlog: 2}})
+
+		expected := `
+# HELP diablo_secondary_transactions_sent_total Number of transactions sent.
+# TYPE diablo_secondary_transactions_sent_total counter
+diablo_secondary_transactions_sent_total 15
+# HELP diablo_secondary_transactions_pending Number of transactions sent and not done yet.
+# TYPE diablo_secondary_transactions_pending gauge
+diablo_secondary_transactions_pending 6
+# HELP diablo_secondary_backlog_transactions Number of transactions of the elapsed intervals of the schedule not sent yet.
+# TYPE diablo_secondary_backlog_transactions gauge
+diablo_secondary_backlog_transactions 2
+`
+		err := testutil.GatherAndCompare(m.registry, strings.NewReader(expected),
+			"diablo_secondary_transactions_sent_total",
+			"diablo_secondary_transactions_pending",
+			"diablo_secondary_backlog_transactions")
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("observed transactions", func(t *testing.T) {
+		m := NewSecondaryMetrics()
+		m.ObserveCommit(250)
+		m.ObserveCommit(1500)
+		m.ObserveFailure(results.ErrorTimeout)
+		m.ObserveFailure("")
+
+		if v := testutil.ToFloat64(m.committed); v != 2 {
+			t.Errorf("expected 2 committed transactions, got %v", v)
+		}
+		if v := testutil.ToFloat64(m.failed.WithLabelValues(string(results.ErrorTimeout))); v != 1 {
+			t.Errorf("expected 1 timeout, got %v", v)
+		}
+		if v := testutil.ToFloat64(m.failed.WithLabelValues(string(results.ErrorOther))); v != 1 {
+			t.Errorf("expected an unclassified failure counted as other, got %v", v)
+		}
+	})
+}
+
+func TestPrimaryMetrics(t *testing.T) {
+	t.Run("nil metrics record nothing", func(t *testing.T) {
+		var m *PrimaryMetrics
+		m.SetPhase(PhaseRunning)
+		m.SetSecondaries(2)
+		m.ObserveResults(&results.AggregatedResults{})
+	})
+
+	t.Run("phase and results", func(t *testing.T) {
+		m := NewPrimaryMetrics()
+		m.SetPhase(PhaseGenerating)
+		m.SetPhase(PhaseRunning)
+		m.ObserveResults(&results.AggregatedResults{TotalSuccess: 90, TotalFails: 10, AverageThroughput: 45, AverageLatency: 500})
+
+		if v := testutil.ToFloat64(m.phase.WithLabelValues(string(PhaseRunning))); v != 1 {
+			t.Errorf("expected the running phase to be set, got %v", v)
+		}
+		if v := testutil.ToFloat64(m.phase.WithLabelValues(string(PhaseGenerating))); v != 0 {
+			t.Errorf("expected the generating phase to be unset, got %v", v)
+		}
+		if v := testutil.ToFloat64(m.committed); v != 90 {
+			t.Errorf("expected 90 committed transactions, got %v", v)
+		}
+		if v := testutil.ToFloat64(m.latency.WithLabelValues("average")); v != 0.5 {
+			t.Errorf("expected an average latency of 0.5s, got %v", v)
+		}
+	})
+}
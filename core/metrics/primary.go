@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"diablo-benchmark/core/results"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Phase is a phase of the benchmark run by the primary
+type Phase string
+
+// Phases of the benchmark run by the primary
+const (
+	PhaseConnecting   Phase = "connecting"   // Waiting for the secondaries to connect
+	PhasePreparing    Phase = "preparing"    // Preparing the secondaries
+	PhaseGenerating   Phase = "generating"   // Generating the workload
+	PhaseDistributing Phase = "distributing" // Sending the workload to the secondaries
+	PhaseRunning      Phase = "running"      // Running the workload on the secondaries
+	PhaseResults      Phase = "results"      // Collecting and aggregating the results
+	PhaseDone         Phase = "done"         // All the benchmarks are over
+)
+
+// phases lists the phases of the benchmark, in order
+var phases = []Phase{PhaseConnecting, PhasePreparing, PhaseGenerating, PhaseDistributing, PhaseRunning, PhaseResults, PhaseDone}
+
+// PrimaryMetrics are the metrics of the primary: the secondaries connected, the
+// phase of the benchmark and the aggregated results of the benchmarks run.
+// A nil PrimaryMetrics records nothing so that the metrics remain optional.
+type PrimaryMetrics struct {
+	registry    *prometheus.Registry // Registry of the metrics served
+	secondaries prometheus.Gauge     // Secondaries connected
+	phase       *prometheus.GaugeVec // 1 for the current phase, 0 for the others
+	benchmarks  prometheus.Counter   // Benchmarks completed
+	committed   prometheus.Counter   // Transactions committed over all benchmarks
+	failed      prometheus.Counter   // Transactions failed over all benchmarks
+	throughput  prometheus.Gauge     // Throughput of the last benchmark [tx/s]
+	latency     *prometheus.GaugeVec // Latencies of the last benchmark [s]
+}
+
+// NewPrimaryMetrics creates the metrics of the primary
+func NewPrimaryMetrics() *PrimaryMetrics {
+	m := &PrimaryMetrics{
+		registry: newRegistry(),
+		secondaries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "secondaries_connected",
+			Help:      "Number of secondaries connected to the primary.",
+		}),
+		phase: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "phase",
+			Help:      "Phase of the benchmark, 1 for the current phase.",
+		}, []string{"phase"}),
+		benchmarks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "benchmarks_completed_total",
+			Help:      "Number of benchmarks completed.",
+		}),
+		committed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "transactions_committed_total",
+			Help:      "Number of transactions committed, over all secondaries.",
+		}),
+		failed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "transactions_failed_total",
+			Help:      "Number of transactions failed, over all secondaries.",
+		}),
+		throughput: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "throughput_tps",
+			Help:      "Throughput of the last benchmark, over all secondaries.",
+		}),
+		latency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "primary",
+			Name:      "latency_seconds",
+			Help:      "Latency of the transactions of the last benchmark, by statistic.",
+		}, []string{"stat"}),
+	}
+
+	m.registry.MustRegister(m.secondaries, m.phase, m.benchmarks, m.committed, m.failed, m.throughput, m.latency)
+
+	return m
+}
+
+// Serve serves the metrics on the given address
+func (m *PrimaryMetrics) Serve(addr string) error {
+	return serve(addr, m.registry)
+}
+
+// SetPhase sets the current phase of the benchmark
+func (m *PrimaryMetrics) SetPhase(phase Phase) {
+	if m == nil {
+		return
+	}
+
+	for _, v := range phases {
+		value := float64(0)
+		if v == phase {
+			value = 1
+		}
+		m.phase.WithLabelValues(string(v)).Set(value)
+	}
+}
+
+// SetSecondaries sets the number of secondaries connected
+func (m *PrimaryMetrics) SetSecondaries(n int) {
+	if m == nil {
+		return
+	}
+
+	m.secondaries.Set(float64(n))
+}
+
+// ObserveResults records the aggregated results of a completed benchmark
+func (m *PrimaryMetrics) ObserveResults(res *results.AggregatedResults) {
+	if m == nil {
+		return
+	}
+
+	m.benchmarks.Inc()
+	m.committed.Add(float64(res.TotalSuccess))
+	m.failed.Add(float64(res.TotalFails))
+	m.throughput.Set(res.AverageThroughput)
+	m.latency.WithLabelValues("average").Set(res.AverageLatency / 1000)
+	m.latency.WithLabelValues("p50").Set(res.Percentiles.P50 / 1000)
+	m.latency.WithLabelValues("p90").Set(res.Percentiles.P90 / 1000)
+	m.latency.WithLabelValues("p99").Set(res.Percentiles.P99 / 1000)
+}
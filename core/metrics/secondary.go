@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"diablo-benchmark/core/handlers"
+	"diablo-benchmark/core/results"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// latencyBuckets are the upper bounds of the buckets of the commit latency
+// histogram, from 5ms to about 3 minutes [s]
+var latencyBuckets = prometheus.ExponentialBuckets(0.005, 2, 16)
+
+// Source provides the live counters of the workload of a benchmark
+type Source interface {
+	Stats() handlers.Stats
+}
+
+// SecondaryMetrics are the metrics of a secondary: the transactions sent,
+// committed and failed, the commit latencies and the backlog behind the schedule.
+// The counters keep growing over the benchmarks run by the secondary (e.g. sweeps).
+type SecondaryMetrics struct {
+	registry   *prometheus.Registry   // Registry of the metrics served
+	sourceLock sync.Mutex             // Guards the source and the base
+	source     Source                 // Workload of the current benchmark, nil before the first
+	base       handlers.Stats         // Counters of the previous benchmarks
+	committed  prometheus.Counter     // Transactions committed
+	failed     *prometheus.CounterVec // Transactions failed by error class
+	latency    prometheus.Histogram   // Commit latencies [s]
+}
+
+// NewSecondaryMetrics creates the metrics of a secondary
+func NewSecondaryMetrics() *SecondaryMetrics {
+	m := &SecondaryMetrics{
+		registry: newRegistry(),
+		committed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "transactions_committed_total",
+			Help:      "Number of transactions committed.",
+		}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "transactions_failed_total",
+			Help:      "Number of transactions failed, by error class.",
+		}, []string{"class"}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "commit_latency_seconds",
+			Help:      "Latency from the send to the commit of the transactions.",
+			Buckets:   latencyBuckets,
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.committed,
+		m.failed,
+		m.latency,
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "transactions_sent_total",
+			Help:      "Number of transactions sent.",
+		}, func() float64 { return float64(m.stats().Sent) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "transactions_done_total",
+			Help:      "Number of transactions done (committed or failed).",
+		}, func() float64 { return float64(m.stats().Done) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "transactions_pending",
+			Help:      "Number of transactions sent and not done yet.",
+		}, func() float64 {
+			s := m.stats()
+			if s.Sent < s.Done {
+				return 0
+			}
+			return float64(s.Sent - s.Done)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "secondary",
+			Name:      "backlog_transactions",
+			Help:      "Number of transactions of the elapsed intervals of the schedule not sent yet.",
+		}, func() float64 { return float64(m.stats().Backlog) }),
+	)
+
+	return m
+}
+
+// Serve serves the metrics on the given address
+func (m *SecondaryMetrics) Serve(addr string) error {
+	return serve(addr, m.registry)
+}
+
+// SetSource sets the workload of the benchmark that is about to run, the
+// counters of the previous workload are kept in the totals
+func (m *SecondaryMetrics) SetSource(source Source) {
+	m.sourceLock.Lock()
+	defer m.sourceLock.Unlock()
+
+	if m.source != nil {
+		previous := m.source.Stats()
+		m.base.Sent += previous.Sent
+		m.base.Done += previous.Done
+		m.base.Errors += previous.Errors
+	}
+	m.source = source
+}
+
+// stats returns the counters of all the benchmarks and the backlog of the current one
+func (m *SecondaryMetrics) stats() handlers.Stats {
+	m.sourceLock.Lock()
+	defer m.sourceLock.Unlock()
+
+	s := m.base
+	if m.source != nil {
+		current := m.source.Stats()
+		s.Sent += current.Sent
+		s.Done += current.Done
+		s.Errors += current.Errors
+		s.Backlog = current.Backlog
+	}
+
+	return s
+}
+
+// ObserveCommit records the latency (ms) of a committed transaction
+func (m *SecondaryMetrics) ObserveCommit(latency float64) {
+	m.committed.Inc()
+	m.latency.Observe(latency / 1000)
+}
+
+// ObserveFailure records the error class of a failed transaction
+func (m *SecondaryMetrics) ObserveFailure(class results.ErrorClass) {
+	if class == "" {
+		class = results.ErrorOther
+	}
+	m.failed.WithLabelValues(string(class)).Inc()
+}
@@ -9,19 +9,34 @@ import (
 	"diablo-benchmark/blockchains/workloadgenerators"
 	"diablo-benchmark/communication"
 	"diablo-benchmark/core/configs"
+	"diablo-benchmark/core/configs/parsers"
+	"diablo-benchmark/core/hooks"
+	"diablo-benchmark/core/metrics"
 	"diablo-benchmark/core/results"
+	"errors"
 	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+// ErrAssertionsFailed is returned by the benchmark run when the results did not
+// satisfy the assertions defined in the benchmark configuration
+var ErrAssertionsFailed = errors.New("benchmark assertions failed")
+
+// ResultsDir is the directory that the results and any related output are written to
+const ResultsDir = "results"
+
 // Primary benchmark server, acts as the orchestrator for the benchmark
 type Primary struct {
 	Server            *communication.PrimaryServer         // TCP server identified with the primary for all secondaries to connect to
 	workloadGenerator workloadgenerators.WorkloadGenerator // Workload generator implementation that will generate the transactions
 	benchmarkConfig   *configs.BenchConfig                 // Benchmark configuration about the workload
 	chainConfig       *configs.ChainConfig                 // Chain configuration containing information about the nodes
+	hooks             *hooks.Runner                        // Lifecycle hooks run between the phases of the benchmark
+	Exports           []results.ExportFormat               // Formats the results are exported to besides the JSON results
+	Metrics           *metrics.PrimaryMetrics              // Metrics served to Prometheus, nil if not served
+	runID             string                               // Identifier of the run, recorded in the results
 }
 
 // InitPrimary initialises the primary server and returns an instance of the primary
@@ -34,12 +49,33 @@ func InitPrimary(listenAddr string, expectedSecondaries int, wg workloadgenerato
 	}
 
 	// Return a new primary instance with the active communication set up
-	return &Primary{
+	p := &Primary{
 		Server:            s,
 		workloadGenerator: wg,
 		benchmarkConfig:   bConfig,
 		chainConfig:       cConfig,
+		runID:             time.Now().Format("20060102T150405"),
 	}
+	p.hooks = p.newHookRunner(p.runID, bConfig)
+
+	return p
+}
+
+// newHookRunner creates the runner for the lifecycle hooks that run on the
+// primary for the run of the given benchmark configuration
+func (p *Primary) newHookRunner(runID string, bConfig *configs.BenchConfig) *hooks.Runner {
+	return hooks.NewRunner(bConfig.Hooks, hooks.RunInfo{
+		RunID:           runID,
+		Role:            hooks.RolePrimary,
+		BenchName:       bConfig.Name,
+		BenchConfigPath: bConfig.Path,
+		ChainName:       p.chainConfig.Name,
+		ChainConfigPath: p.chainConfig.Path,
+		Nodes:           p.chainConfig.Nodes,
+		Secondaries:     bConfig.Secondaries,
+		Threads:         bConfig.Threads,
+		ResultsDir:      ResultsDir,
+	})
 }
 
 // closeAllConns closes all connections and exits
@@ -48,95 +84,316 @@ func (p *Primary) closeAllConns() {
 	p.Server.Close()
 }
 
-// Run provides the main functionality to run
-// Holds the majority of the work
-// TODO: under construction!
-func (p *Primary) Run() {
+// countWorkloadTransactions returns the total number of transactions in the workload
+func countWorkloadTransactions(workload workloadgenerators.Workload) uint {
+	total := uint(0)
+	for _, secondaryWorkload := range workload {
+		for _, threadWorkload := range secondaryWorkload {
+			for _, intervalWorkload := range threadWorkload {
+				total += uint(len(intervalWorkload))
+			}
+		}
+	}
+
+	return total
+}
+
+// countSpecTransactions returns the total number of transactions described by the workload specs
+func countSpecTransactions(specs []workloadgenerators.WorkloadSpec) uint {
+	total := uint(0)
+	for _, spec := range specs {
+		total += uint(spec.NumTransactions())
+	}
+
+	return total
+}
+
+// generateWorkloadSpecs generates the workload specs if the secondaries are
+// configured to generate the workload. Returns nil specs if the workload is
+// generated on the primary, which is also the fallback if the generator does
+// not support specs.
+func generateWorkloadSpecs(wg workloadgenerators.WorkloadGenerator, bConfig *configs.BenchConfig) ([]workloadgenerators.WorkloadSpec, error) {
+	if bConfig.TxInfo.Generation.Mode != configs.GenerationSecondary {
+		return nil, nil
+	}
+
+	specs, err := wg.GenerateWorkloadSpecs()
+	if err == workloadgenerators.ErrWorkloadSpecNotSupported {
+		zap.L().Warn("workload cannot be generated on the secondaries, generating it on the primary",
+			zap.String("type", string(bConfig.TxInfo.TxType)))
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(specs) != bConfig.Secondaries {
+		return nil, fmt.Errorf("generated %d workload specs for %d secondaries", len(specs), bConfig.Secondaries)
+	}
+
+	return specs, nil
+}
+
+// setupGenerator sets up the blockchain and initialises the workload generator
+func setupGenerator(wg workloadgenerators.WorkloadGenerator) error {
 	// First, set up the blockchain
-	err := p.workloadGenerator.BlockchainSetup()
+	err := wg.BlockchainSetup()
 
 	if err != nil {
 		zap.L().Error("encountered error with blockchain setup",
 			zap.String("error", err.Error()))
-		return
+		return err
 	}
 
 	// Next, init the workload generator
-	err = p.workloadGenerator.InitParams()
+	err = wg.InitParams()
 	if err != nil {
 		zap.L().Error("encountered error with workloadgenerator InitParams",
 			zap.String("error", err.Error()))
-		return
+		return err
+	}
+
+	return nil
+}
+
+// Run provides the main functionality to run
+// Holds the majority of the work, returns an error if the benchmark could not
+// be completed, or ErrAssertionsFailed if the results did not pass the assertions.
+// TODO: under construction!
+func (p *Primary) Run() error {
+	// A sweep campaign sets up a new generator for every combination
+	isSweep := parsers.HasSweep(p.benchmarkConfig)
+
+	if !isSweep {
+		if err := setupGenerator(p.workloadGenerator); err != nil {
+			return err
+		}
 	}
 
 	// Get the secondary connections ready
+	p.Metrics.SetPhase(metrics.PhaseConnecting)
 	secondaryReadyChannel := make(chan bool, 1)
 	go p.Server.HandleSecondaries(secondaryReadyChannel)
 	<-secondaryReadyChannel
 	close(secondaryReadyChannel)
+	p.Metrics.SetSecondaries(len(p.Server.Secondaries))
+
+	if isSweep {
+		return p.runSweep()
+	}
+
+	aggregatedResults, err := p.runBenchmark(p.Server, p.workloadGenerator, p.benchmarkConfig, p.runID)
+	if err != nil {
+		p.closeAllConns()
+		return err
+	}
+
+	// Step 7 - store results
+	p.Server.SendFin()
+
+	time.Sleep(2 * time.Second)
+
+	// Display the results
+	results.Display(aggregatedResults)
+	// Write the results to a file
+	err = results.WriteResultsToFile(p.benchmarkConfig.Path, p.chainConfig.Path, aggregatedResults, ResultsDir, p.Exports)
+	if err != nil {
+		zap.L().Error("Encountered error when saving results",
+			zap.Error(err))
+	}
+
+	// Step 8: Close all connections
+	p.Server.CloseSecondaries()
+	p.Server.Close()
+	p.Metrics.SetSecondaries(0)
+	p.Metrics.SetPhase(metrics.PhaseDone)
+
+	if err = p.hooks.RunPhase(configs.HookPhaseResults); err != nil {
+		return err
+	}
+
+	if !aggregatedResults.AssertionsPassed {
+		return ErrAssertionsFailed
+	}
+
+	return nil
+}
+
+// runSweep runs the benchmark for every combination of the sweep parameters,
+// one after the other on the connected secondaries, and writes the matrix report.
+func (p *Primary) runSweep() error {
+	combinations := parsers.ExpandSweep(p.benchmarkConfig)
+	var sweepResults []results.SweepResult
+	passed := true
+
+	for i, combination := range combinations {
+		zap.L().Info(fmt.Sprintf("Running sweep combination %d / %d", i+1, len(combinations)),
+			zap.Int("threads", combination.Point.Threads),
+			zap.Int("secondaries", combination.Point.Secondaries),
+			zap.Int("tps", combination.Point.TPS))
+
+		// Every combination has its own generator so that the workload is
+		// generated from the current state of the chain (e.g. nonces).
+		wg := p.workloadGenerator.NewGenerator(p.chainConfig, combination.Config)
+		err := setupGenerator(wg)
+		if err != nil {
+			p.closeAllConns()
+			return err
+		}
+
+		// Every combination is a run of its own, so that their hook outputs do not overwrite each other
+		runID := fmt.Sprintf("%s-%d", p.runID, i+1)
+		aggregatedResults, err := p.runBenchmark(p.Server.WithSecondaries(combination.Point.Secondaries), wg, combination.Config, runID)
+		if err != nil {
+			p.closeAllConns()
+			return err
+		}
+
+		// The configuration written with the results is the sweep template
+		point := combination.Point
+		aggregatedResults.Metadata.Sweep = &point
 
-	// Parse the config files
-	// Run all preparation
+		results.Display(aggregatedResults)
+		err = results.WriteResultsToFile(p.benchmarkConfig.Path, p.chainConfig.Path, aggregatedResults, ResultsDir, p.Exports)
+		if err != nil {
+			zap.L().Error("Encountered error when saving results",
+				zap.Error(err))
+		}
+
+		passed = passed && aggregatedResults.AssertionsPassed
+		sweepResults = append(sweepResults, results.NewSweepResult(combination.Point, aggregatedResults))
+	}
+
+	p.Server.SendFin()
+
+	time.Sleep(2 * time.Second)
+
+	results.DisplaySweep(sweepResults)
+	err := results.WriteSweepReport(sweepResults, ResultsDir)
+	if err != nil {
+		zap.L().Error("Encountered error when saving sweep report",
+			zap.Error(err))
+	}
+
+	p.Server.CloseSecondaries()
+	p.Server.Close()
+	p.Metrics.SetSecondaries(0)
+	p.Metrics.SetPhase(metrics.PhaseDone)
+
+	if err = p.hooks.RunPhase(configs.HookPhaseResults); err != nil {
+		return err
+	}
+
+	if !passed {
+		return ErrAssertionsFailed
+	}
+
+	return nil
+}
+
+// runBenchmark runs a single benchmark with the given configuration on the
+// secondaries of the server: prepares the secondaries, generates and
+// distributes the workload, runs it and returns the aggregated results. The
+// run ID is shared with the hooks of the primary and secondaries.
+func (p *Primary) runBenchmark(server *communication.PrimaryServer, wg workloadgenerators.WorkloadGenerator, bConfig *configs.BenchConfig, runID string) (results.AggregatedResults, error) {
+	runHooks := p.newHookRunner(runID, bConfig)
 
 	// Run through the benchmark suite
 	// Step 1: send "PREPARE" to secondaries, make sure we can communicate.
-	errs := p.Server.PrepareBenchmarkSecondaries(uint32(p.benchmarkConfig.Threads))
+	p.Metrics.SetPhase(metrics.PhasePreparing)
+	errs := server.PrepareBenchmarkSecondaries(uint32(bConfig.Threads), runID)
 
 	if errs != nil {
 		// We have errors
-		p.closeAllConns()
 		zap.L().Error("Encountered errors in secondaries",
 			zap.Strings("errors", errs))
-		return
+		return results.AggregatedResults{}, fmt.Errorf("errors preparing secondaries: %v", errs)
 	}
 
 	// Number of secondaries connected
 	zap.L().Info("Benchmark secondaries all connected.",
-		zap.Int("secondaries", len(p.Server.Secondaries)))
+		zap.Int("secondaries", len(server.Secondaries)))
 
-	p.workloadGenerator.SetThreadIntervals(workloadgenerators.GetIntervalPerThread(p.benchmarkConfig.TxInfo.Intervals, p.benchmarkConfig.Secondaries, p.benchmarkConfig.Threads))
+	if err := runHooks.RunPhase(configs.HookPhaseSetup); err != nil {
+		return results.AggregatedResults{}, err
+	}
 
-	// Step 3: Prepare the workload for the benchmark
-	// TODO: generate workloads
-	workload, err := p.workloadGenerator.GenerateWorkload()
+	p.Metrics.SetPhase(metrics.PhaseGenerating)
+	wg.SetThreadIntervals(workloadgenerators.GetIntervalPerThread(bConfig.TxInfo.Intervals, bConfig.Secondaries, bConfig.Threads))
 
+	// Step 3: Prepare the workload for the benchmark, either as specs that
+	// the secondaries expand or as the full workload
+	specs, err := generateWorkloadSpecs(wg, bConfig)
 	if err != nil {
-		zap.L().Error("failed to generate workload",
+		zap.L().Error("failed to generate workload specs",
 			zap.String("error", err.Error()))
-		p.closeAllConns()
-		return
-	} else if workload == nil || len(workload) == 0 {
-		zap.L().Error("failed to produce workload")
-		p.closeAllConns()
-		return
+		return results.AggregatedResults{}, err
+	}
+
+	var workload workloadgenerators.Workload
+	var workloadTx uint
+	if specs != nil {
+		workloadTx = countSpecTransactions(specs)
+	} else {
+		// TODO: generate workloads
+		workload, err = wg.GenerateWorkload()
+
+		if err != nil {
+			zap.L().Error("failed to generate workload",
+				zap.String("error", err.Error()))
+			return results.AggregatedResults{}, err
+		} else if workload == nil || len(workload) == 0 {
+			zap.L().Error("failed to produce workload")
+			return results.AggregatedResults{}, errors.New("failed to produce workload")
+		}
+
+		workloadTx = countWorkloadTransactions(workload)
+	}
+
+	if err = runHooks.RunPhase(configs.HookPhaseWorkload); err != nil {
+		return results.AggregatedResults{}, err
 	}
 
 	// Step 4: Distribute benchmark
-	errs = p.Server.SendWorkload(workload)
+	p.Metrics.SetPhase(metrics.PhaseDistributing)
+	if specs != nil {
+		errs = server.SendWorkloadSpecs(specs)
+	} else {
+		errs = server.SendWorkload(workload)
+	}
 	if errs != nil {
 		zap.L().Error("Encountered Error sending workload",
 			zap.String("errs", fmt.Sprintf("%v", errs)),
 		)
-		p.closeAllConns()
-		return
+		return results.AggregatedResults{}, fmt.Errorf("errors sending workload: %v", errs)
+	}
+
+	if err = runHooks.RunPhase(configs.HookPhaseRunStart); err != nil {
+		return results.AggregatedResults{}, err
 	}
 
 	// Step 5: run the bench
-	errs = p.Server.RunBenchmark()
+	p.Metrics.SetPhase(metrics.PhaseRunning)
+	start := time.Now()
+	errs = server.RunBenchmark()
+	end := time.Now()
 	if errs != nil {
 		zap.L().Error("Encountered Error sending workload",
 			zap.String("errs", fmt.Sprintf("%v", errs)),
 		)
-		p.closeAllConns()
-		return
+		return results.AggregatedResults{}, fmt.Errorf("errors running benchmark: %v", errs)
 	}
 
 	// Wait until everyone is done and give some room for final messages
 	time.Sleep(2 * time.Second)
 
+	if err = runHooks.RunPhase(configs.HookPhaseRunEnd); err != nil {
+		return results.AggregatedResults{}, err
+	}
+
 	// Step 6 (once all have completed) - get the results
+	p.Metrics.SetPhase(metrics.PhaseResults)
 	// TODO: Need to store the results
-	rawResults, errs := p.Server.GetResults()
+	rawResults, errs := server.GetResults()
 	if errs != nil {
 		zap.L().Error("GetResults returned client errors",
 			zap.Strings("errors", errs))
@@ -146,21 +403,67 @@ func (p *Primary) Run() {
 	// TODO: @CHRIS
 	aggregatedResults := results.CalculateAggregatedResults(rawResults)
 
-	// Step 7 - store results
-	p.Server.SendFin()
+	aggregatedResults.Metadata = results.NewMetadata(runID, start, end, p.benchmarkConfig.Path, p.chainConfig.Path)
+	var addresses []string
+	for _, c := range server.Secondaries {
+		addresses = append(addresses, c.RemoteAddr().String())
+	}
+	aggregatedResults.Metadata.AddSecondaries(addresses, rawResults)
 
-	time.Sleep(2 * time.Second)
+	analyseResults(&aggregatedResults, bConfig, wg.ContractFunctions(), workloadTx)
 
-	// Display the results
-	results.Display(aggregatedResults)
-	// Write the results to a file
-	err = results.WriteResultsToFile(p.benchmarkConfig.Path, p.chainConfig.Path, aggregatedResults, "results")
-	if err != nil {
-		zap.L().Error("Encountered error when saving results",
-			zap.Error(err))
+	p.Metrics.ObserveResults(&aggregatedResults)
+
+	return aggregatedResults, nil
+}
+
+// analyseResults computes the analyses of the results aggregated from the
+// secondaries, with the contract functions of the workload keyed as the
+// transactions record them and the number of transactions of the workload
+func analyseResults(res *results.AggregatedResults, bConfig *configs.BenchConfig, functions map[string]configs.ContractFunction, workloadTx uint) {
+	// Latency from the scheduled send times, to expose queueing within Diablo
+	lagThreshold := bConfig.TxInfo.LagThreshold
+	if lagThreshold <= 0 {
+		lagThreshold = configs.DefaultLagThreshold
 	}
+	// In closed loop the sends are driven by the completions, not by a schedule
+	if bConfig.TxInfo.Load.Mode == configs.LoadClosed {
+		lagThreshold = 0
+	}
+	results.CalculateScheduleLatencies(res, time.Duration(lagThreshold)*time.Millisecond)
 
-	// Step 8: Close all connections
-	p.Server.CloseSecondaries()
-	p.Server.Close()
+	// Latency of the stages of the transactions measured by the secondaries
+	results.CalculateLatencyStages(res)
+
+	// Throughput over time in windows aligned across all secondaries
+	throughputWindow := bConfig.Results.ThroughputWindow
+	if throughputWindow <= 0 {
+		throughputWindow = configs.DefaultThroughputWindow
+	}
+	results.CalculateThroughputWindows(res, time.Duration(throughputWindow)*time.Millisecond)
+
+	results.CalculateErrorBreakdown(res)
+
+	// Results per function and type of function called by the transactions
+	functionInfo := make(map[string]results.FunctionInfo)
+	for id, f := range functions {
+		functionInfo[id] = results.FunctionInfo{Name: f.Name, Type: f.Type}
+	}
+	results.ResolveFunctions(res, functionInfo)
+	results.CalculateFunctionBreakdown(res)
+
+	// Analytics of the blocks walked by the first secondary
+	results.CalculateBlockAnalytics(res)
+
+	// Resources used by the secondaries, warning if they are the bottleneck
+	results.CalculateResourceUsage(res)
+
+	// Report the throughput reached at the concurrency of the closed loop
+	if bConfig.TxInfo.Load.Mode == configs.LoadClosed {
+		res.Concurrency = bConfig.TxInfo.Load.Outstanding * bConfig.Threads * len(res.RawResults)
+		res.EffectiveConcurrency = res.AverageThroughput * res.AverageLatency / 1000
+	}
+
+	// Check the results against the pass/fail criteria
+	results.EvaluateAssertions(bConfig.Assertions, res, workloadTx)
 }
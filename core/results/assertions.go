@@ -0,0 +1,88 @@
+package results
+
+import (
+	"diablo-benchmark/core/configs"
+)
+
+// Names of the assertions that can be defined in the benchmark configuration
+const (
+	AssertMinThroughput        = "min_throughput"
+	AssertMaxP99Latency        = "max_p99_latency"
+	AssertMaxFailureRatio      = "max_failure_ratio"
+	AssertMinCommittedFraction = "min_committed_fraction"
+)
+
+// AssertionResult is the outcome of a single pass/fail assertion evaluated
+// against the aggregated results.
+type AssertionResult struct {
+	Name      string  `json:"Name"`      // Name of the assertion (as defined in the config)
+	Threshold float64 `json:"Threshold"` // Threshold defined in the benchmark configuration
+	Actual    float64 `json:"Actual"`    // Value measured in the benchmark
+	Passed    bool    `json:"Passed"`    // Whether the measured value satisfied the threshold
+}
+
+// EvaluateAssertions checks the assertions of the benchmark configuration against the
+// aggregated results. The outcome is stored in the results and returned, true if all
+// assertions passed. The total number of transactions in the workload is used to calculate
+// the fraction of the workload that was committed.
+func EvaluateAssertions(assertions configs.Assertions, res *AggregatedResults, workloadTx uint) bool {
+	var outcomes []AssertionResult
+
+	if assertions.MinThroughput != nil {
+		outcomes = append(outcomes, AssertionResult{
+			Name:      AssertMinThroughput,
+			Threshold: *assertions.MinThroughput,
+			Actual:    res.AverageThroughput,
+			Passed:    res.AverageThroughput >= *assertions.MinThroughput,
+		})
+	}
+
+	if assertions.MaxP99Latency != nil {
+		p99 := LatencyPercentile(res, 99)
+
+		outcomes = append(outcomes, AssertionResult{
+			Name:      AssertMaxP99Latency,
+			Threshold: *assertions.MaxP99Latency,
+			Actual:    p99,
+			Passed:    res.numLatencies() > 0 && p99 <= *assertions.MaxP99Latency,
+		})
+	}
+
+	if assertions.MaxFailureRatio != nil {
+		failureRatio := float64(0)
+		if total := res.TotalSuccess + res.TotalFails; total > 0 {
+			failureRatio = float64(res.TotalFails) / float64(total)
+		}
+
+		outcomes = append(outcomes, AssertionResult{
+			Name:      AssertMaxFailureRatio,
+			Threshold: *assertions.MaxFailureRatio,
+			Actual:    failureRatio,
+			Passed:    failureRatio <= *assertions.MaxFailureRatio,
+		})
+	}
+
+	if assertions.MinCommittedFraction != nil {
+		committedFraction := float64(0)
+		if workloadTx > 0 {
+			committedFraction = float64(res.TotalSuccess) / float64(workloadTx)
+		}
+
+		outcomes = append(outcomes, AssertionResult{
+			Name:      AssertMinCommittedFraction,
+			Threshold: *assertions.MinCommittedFraction,
+			Actual:    committedFraction,
+			Passed:    committedFraction >= *assertions.MinCommittedFraction,
+		})
+	}
+
+	passed := true
+	for _, v := range outcomes {
+		passed = passed && v.Passed
+	}
+
+	res.Assertions = outcomes
+	res.AssertionsPassed = passed
+
+	return passed
+}
@@ -0,0 +1,83 @@
+package results
+
+import (
+	"diablo-benchmark/core/configs"
+	"testing"
+)
+
+func TestEvaluateAssertions(t *testing.T) {
+
+	float := func(v float64) *float64 {
+		return &v
+	}
+
+	sampleResults := func() AggregatedResults {
+		return AggregatedResults{
+			AverageThroughput: 100,
+			AllTxLatencies:    []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 1000},
+			TotalSuccess:      90,
+			TotalFails:        10,
+		}
+	}
+
+	t.Run("no assertions passes", func(t *testing.T) {
+		res := sampleResults()
+
+		if !EvaluateAssertions(configs.Assertions{}, &res, 100) {
+			t.Errorf("expected empty assertions to pass")
+		}
+
+		if len(res.Assertions) != 0 {
+			t.Errorf("expected no assertion results, got %d", len(res.Assertions))
+		}
+	})
+
+	t.Run("all assertions pass", func(t *testing.T) {
+		res := sampleResults()
+		a := configs.Assertions{
+			MinThroughput:        float(50),
+			MaxP99Latency:        float(1000),
+			MaxFailureRatio:      float(0.1),
+			MinCommittedFraction: float(0.9),
+		}
+
+		if !EvaluateAssertions(a, &res, 100) {
+			t.Errorf("expected assertions to pass: %+v", res.Assertions)
+		}
+
+		if len(res.Assertions) != 4 || !res.AssertionsPassed {
+			t.Errorf("expected 4 passing assertion results, got %+v", res.Assertions)
+		}
+	})
+
+	t.Run("failing assertions", func(t *testing.T) {
+		res := sampleResults()
+		a := configs.Assertions{
+			MinThroughput: float(50),
+			MaxP99Latency: float(500),
+		}
+
+		if EvaluateAssertions(a, &res, 100) {
+			t.Errorf("expected p99 latency assertion to fail")
+		}
+
+		if !res.Assertions[0].Passed || res.Assertions[1].Passed {
+			t.Errorf("unexpected assertion results: %+v", res.Assertions)
+		}
+
+		if res.Assertions[1].Actual != 1000 {
+			t.Errorf("p99 mismatch: expected 1000, got %v", res.Assertions[1].Actual)
+		}
+	})
+
+	t.Run("committed fraction of workload", func(t *testing.T) {
+		res := sampleResults()
+		a := configs.Assertions{
+			MinCommittedFraction: float(0.5),
+		}
+
+		if EvaluateAssertions(a, &res, 200) {
+			t.Errorf("expected committed fraction to fail, got %+v", res.Assertions)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package results
+
+import (
+	"sort"
+	"time"
+)
+
+// BlockRecord is a block produced by the chain during the benchmark
+type BlockRecord struct {
+	Number       uint64 `json:"Number"`             // Number (height) of the block
+	Timestamp    int64  `json:"Timestamp"`          // Timestamp of the block (unix nanoseconds)
+	Transactions int    `json:"Transactions"`       // Number of transactions in the block
+	Diablo       int    `json:"Diablo"`             // Number of transactions of the block sent by Diablo
+	GasUsed      uint64 `json:"GasUsed,omitempty"`  // Gas used by the transactions of the block, 0 if the chain has no gas
+	GasLimit     uint64 `json:"GasLimit,omitempty"` // Gas limit of the block, 0 if the chain has no gas
+}
+
+// Distribution summarises the distribution of a value
+type Distribution struct {
+	Average     float64            `json:"Average"`     // Average value
+	Min         float64            `json:"Min"`         // Minimum value
+	Max         float64            `json:"Max"`         // Maximum value
+	Percentiles LatencyPercentiles `json:"Percentiles"` // Percentiles of the values
+}
+
+// BlockStats are the analytics of the blocks produced during the benchmark
+type BlockStats struct {
+	Blocks               uint         `json:"Blocks"`               // Number of blocks produced
+	EmptyBlocks          uint         `json:"EmptyBlocks"`          // Number of blocks without transactions
+	Interval             Distribution `json:"Interval"`             // Time between consecutive blocks [ms]
+	TransactionsPerBlock Distribution `json:"TransactionsPerBlock"` // Transactions per block
+	Fullness             Distribution `json:"Fullness"`             // Gas used over the gas limit of the blocks [%], zero if the chain has no gas
+	DiabloTransactions   uint         `json:"DiabloTransactions"`   // Transactions of the blocks sent by Diablo
+	ForeignTransactions  uint         `json:"ForeignTransactions"`  // Transactions of the blocks sent by other clients
+	DiabloFraction       float64      `json:"DiabloFraction"`       // Fraction of the transactions of the blocks sent by Diablo
+}
+
+// distributionOf returns the distribution of the values, sorting them in place
+func distributionOf(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sort.Float64s(values)
+
+	sum := float64(0)
+	for _, v := range values {
+		sum += v
+	}
+
+	return Distribution{
+		Average:     sum / float64(len(values)),
+		Min:         values[0],
+		Max:         values[len(values)-1],
+		Percentiles: percentilesOf(values),
+	}
+}
+
+// CalculateBlockAnalytics calculates the analytics of the blocks produced
+// during the benchmark, walked by the secondaries, and counts the transactions
+// of each block sent by Diablo from the blocks the transactions were committed
+// in. It does nothing if the secondaries returned no blocks (e.g. the client
+// interface of the chain cannot walk the blocks).
+func CalculateBlockAnalytics(res *AggregatedResults) {
+	blocks := make(map[uint64]BlockRecord)
+	diablo := make(map[uint64]int)
+
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, b := range workerResult.Blocks {
+				blocks[b.Number] = b
+			}
+
+			for _, tx := range workerResult.Transactions {
+				if tx.Block > 0 && tx.Committed > 0 && tx.Error == "" {
+					diablo[tx.Block]++
+				}
+			}
+		}
+	}
+
+	res.Blocks = nil
+	res.BlockStats = BlockStats{}
+	if len(blocks) == 0 {
+		return
+	}
+
+	for number, b := range blocks {
+		b.Diablo = diablo[number]
+		if b.Diablo > b.Transactions {
+			b.Diablo = b.Transactions
+		}
+		res.Blocks = append(res.Blocks, b)
+	}
+
+	sort.Slice(res.Blocks, func(i, j int) bool {
+		return res.Blocks[i].Number < res.Blocks[j].Number
+	})
+
+	var intervals, transactions, fullness []float64
+	stats := BlockStats{Blocks: uint(len(res.Blocks))}
+	for i, b := range res.Blocks {
+		if b.Transactions == 0 {
+			stats.EmptyBlocks++
+		}
+		transactions = append(transactions, float64(b.Transactions))
+		if b.GasLimit > 0 {
+			fullness = append(fullness, 100*float64(b.GasUsed)/float64(b.GasLimit))
+		}
+
+		stats.DiabloTransactions += uint(b.Diablo)
+		stats.ForeignTransactions += uint(b.Transactions - b.Diablo)
+
+		// Only consecutive blocks, the walk may have missed some
+		if i > 0 && res.Blocks[i-1].Number+1 == b.Number {
+			intervals = append(intervals, float64(b.Timestamp-res.Blocks[i-1].Timestamp)/float64(time.Millisecond))
+		}
+	}
+
+	stats.Interval = distributionOf(intervals)
+	stats.TransactionsPerBlock = distributionOf(transactions)
+	stats.Fullness = distributionOf(fullness)
+	if total := stats.DiabloTransactions + stats.ForeignTransactions; total > 0 {
+		stats.DiabloFraction = float64(stats.DiabloTransactions) / float64(total)
+	}
+
+	res.BlockStats = stats
+}
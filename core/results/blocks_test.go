@@ -0,0 +1,63 @@
+package results
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateBlockAnalytics(t *testing.T) {
+	s := int64(time.Second)
+
+	res := CalculateAggregatedResults([][]Results{
+		{{
+			Transactions: []TransactionRecord{
+				{Sent: s, Committed: 2 * s, Block: 11},
+				{Sent: s, Committed: 2 * s, Block: 11},
+				{Sent: s, Error: ErrorTimeout},
+			},
+			Blocks: []BlockRecord{
+				{Number: 10, Timestamp: 10 * s, Transactions: 0, GasUsed: 0, GasLimit: 100},
+				{Number: 11, Timestamp: 12 * s, Transactions: 4, GasUsed: 50, GasLimit: 100},
+				{Number: 13, Timestamp: 20 * s, Transactions: 2, GasUsed: 100, GasLimit: 100},
+			},
+		}},
+		{{
+			Transactions: []TransactionRecord{
+				{Sent: s, Committed: 3 * s, Block: 13},
+			},
+		}},
+	})
+	CalculateBlockAnalytics(&res)
+
+	t.Run("blocks", func(t *testing.T) {
+		b := res.BlockStats
+		if b.Blocks != 3 || b.EmptyBlocks != 1 {
+			t.Errorf("expected 3 blocks with 1 empty, got %+v", b)
+		}
+		if res.Blocks[1].Diablo != 2 || res.Blocks[2].Diablo != 1 {
+			t.Errorf("unexpected Diablo transactions per block %+v", res.Blocks)
+		}
+		// Block 12 was not walked, only the interval between 10 and 11 is known
+		if b.Interval.Max != 2000 || b.Interval.Min != 2000 {
+			t.Errorf("unexpected block interval %+v", b.Interval)
+		}
+		if b.TransactionsPerBlock.Average != 2 || b.Fullness.Average != 50 {
+			t.Errorf("unexpected transactions per block %+v or fullness %+v", b.TransactionsPerBlock, b.Fullness)
+		}
+	})
+
+	t.Run("foreign transactions", func(t *testing.T) {
+		b := res.BlockStats
+		if b.DiabloTransactions != 3 || b.ForeignTransactions != 3 || b.DiabloFraction != 0.5 {
+			t.Errorf("unexpected Diablo and foreign transactions %+v", b)
+		}
+	})
+
+	t.Run("no blocks", func(t *testing.T) {
+		noBlocks := CalculateAggregatedResults([][]Results{{{}}})
+		CalculateBlockAnalytics(&noBlocks)
+		if noBlocks.BlockStats.Blocks != 0 || noBlocks.Blocks != nil {
+			t.Errorf("expected no block analytics, got %+v", noBlocks.BlockStats)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package results
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultRegressionThreshold is the change (%) of a metric, in the worse
+// direction, from which a compared run is reported as a regression
+const DefaultRegressionThreshold = 10
+
+// DefaultAbsoluteRegressionThreshold is the difference of a metric compared as
+// a difference (e.g. the failure rate in percentage points), in the worse
+// direction, from which a compared run is reported as a regression
+const DefaultAbsoluteRegressionThreshold = 1
+
+// CompareMetric is a metric of the results compared between runs
+type CompareMetric struct {
+	Name           string                               // Name of the metric
+	HigherIsBetter bool                                 // Whether an increase is an improvement
+	Absolute       bool                                 // Whether the change is the difference (e.g. of rates) rather than relative
+	Value          func(res *AggregatedResults) float64 // Value of the metric in the results
+}
+
+// CompareMetrics are the metrics compared between runs
+var CompareMetrics = []CompareMetric{
+	{Name: "Throughput [tx/s]", HigherIsBetter: true, Value: func(res *AggregatedResults) float64 { return res.AverageThroughput }},
+	{Name: "Average latency [ms]", Value: func(res *AggregatedResults) float64 { return res.AverageLatency }},
+	{Name: "p50 latency [ms]", Value: func(res *AggregatedResults) float64 { return LatencyPercentile(res, 50) }},
+	{Name: "p90 latency [ms]", Value: func(res *AggregatedResults) float64 { return LatencyPercentile(res, 90) }},
+	{Name: "p99 latency [ms]", Value: func(res *AggregatedResults) float64 { return LatencyPercentile(res, 99) }},
+	{Name: "p99.9 latency [ms]", Value: func(res *AggregatedResults) float64 { return LatencyPercentile(res, 99.9) }},
+	{Name: "Failure rate [%]", Absolute: true, Value: failureRate},
+}
+
+// failureRate returns the percentage of the transactions that failed
+func failureRate(res *AggregatedResults) float64 {
+	total := res.TotalSuccess + res.TotalFails
+	if total == 0 {
+		return 0
+	}
+
+	return float64(res.TotalFails) / float64(total) * 100
+}
+
+// MetricChange is the value of a metric in a compared run and its change from the baseline
+type MetricChange struct {
+	Value      float64 // Value of the metric
+	Change     float64 // Relative change from the baseline (%), or difference for absolute metrics
+	Regression bool    // Whether the change is worse than the threshold
+}
+
+// ComparisonRow is the comparison of a metric between the baseline and the other runs
+type ComparisonRow struct {
+	Metric   string         // Name of the metric
+	Absolute bool           // Whether the changes are differences rather than relative
+	Baseline float64        // Value of the metric in the baseline
+	Changes  []MetricChange // Value and change of the metric in each compared run
+}
+
+// Comparison is the comparison of runs against a baseline
+type Comparison struct {
+	Threshold         float64         // Change (%) in the worse direction from which a metric regressed
+	AbsoluteThreshold float64         // Difference in the worse direction from which an absolute metric regressed
+	Rows              []ComparisonRow // Comparison of each metric
+	Regressions       int             // Number of metrics that regressed, over all compared runs
+}
+
+// metricChange returns the change of the value from the baseline, relative
+// (%) or as the difference for absolute metrics
+func metricChange(metric CompareMetric, baseline float64, value float64) float64 {
+	if metric.Absolute {
+		return value - baseline
+	}
+
+	if baseline == 0 {
+		if value == 0 {
+			return 0
+		}
+		return math.Copysign(math.Inf(1), value)
+	}
+
+	return (value - baseline) / math.Abs(baseline) * 100
+}
+
+// CompareResults compares the runs to the baseline, a metric regressed if it
+// changed in the worse direction by more than the threshold, relative (%) or
+// as the difference for absolute metrics
+func CompareResults(baseline AggregatedResults, runs []AggregatedResults, threshold float64, absoluteThreshold float64) Comparison {
+	comparison := Comparison{Threshold: threshold, AbsoluteThreshold: absoluteThreshold}
+
+	for _, metric := range CompareMetrics {
+		metricThreshold := threshold
+		if metric.Absolute {
+			metricThreshold = absoluteThreshold
+		}
+
+		row := ComparisonRow{
+			Metric:   metric.Name,
+			Absolute: metric.Absolute,
+			Baseline: metric.Value(&baseline),
+		}
+
+		for i := range runs {
+			value := metric.Value(&runs[i])
+			change := metricChange(metric, row.Baseline, value)
+
+			worse := change
+			if metric.HigherIsBetter {
+				worse = -change
+			}
+
+			regression := worse > metricThreshold
+			if regression {
+				comparison.Regressions++
+			}
+
+			row.Changes = append(row.Changes, MetricChange{Value: value, Change: change, Regression: regression})
+		}
+
+		comparison.Rows = append(comparison.Rows, row)
+	}
+
+	return comparison
+}
+
+// formatChange formats the change of a metric
+func formatChange(row ComparisonRow, change MetricChange) string {
+	mark := ""
+	if change.Regression {
+		mark = " !"
+	}
+
+	if row.Absolute {
+		return fmt.Sprintf("%+.3f%s", change.Change, mark)
+	}
+
+	if math.IsInf(change.Change, 0) {
+		return fmt.Sprintf("%+v%%%s", change.Change, mark)
+	}
+
+	return fmt.Sprintf("%+.1f%%%s", change.Change, mark)
+}
+
+// DisplayComparison presents the comparison of the runs to stdout, the first
+// name being the baseline
+func DisplayComparison(names []string, comparison Comparison) {
+	fmt.Println()
+	fmt.Println("--------------------------")
+	fmt.Println("Results Comparison")
+	fmt.Println("--------------------------")
+	for i, v := range names {
+		label := "baseline"
+		if i > 0 {
+			label = fmt.Sprintf("run %d", i)
+		}
+		fmt.Println(fmt.Sprintf("[*] %-8s: %s", label, v))
+	}
+	fmt.Println()
+
+	header := fmt.Sprintf("%-22s %14s", "metric", "baseline")
+	for i := 1; i < len(names); i++ {
+		header += fmt.Sprintf(" %14s %12s", fmt.Sprintf("run %d", i), "change")
+	}
+	fmt.Println(header)
+
+	for _, row := range comparison.Rows {
+		line := fmt.Sprintf("%-22s %14.3f", row.Metric, row.Baseline)
+		for _, change := range row.Changes {
+			line += fmt.Sprintf(" %14.3f %12s", change.Value, formatChange(row, change))
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println()
+	if comparison.Regressions > 0 {
+		fmt.Println(fmt.Sprintf("[!] %d regression(s) beyond the thresholds of %.1f%% and %.3f for differences (marked with !)", comparison.Regressions, comparison.Threshold, comparison.AbsoluteThreshold))
+	} else {
+		fmt.Println(fmt.Sprintf("[*] No regression beyond the thresholds of %.1f%% and %.3f for differences", comparison.Threshold, comparison.AbsoluteThreshold))
+	}
+	fmt.Println()
+}
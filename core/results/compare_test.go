@@ -0,0 +1,78 @@
+package results
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareResults(t *testing.T) {
+	baseline := AggregatedResults{
+		AverageThroughput: 100,
+		AverageLatency:    200,
+		AllTxLatencies:    []float64{100, 200, 300},
+		TotalSuccess:      99,
+		TotalFails:        1,
+	}
+
+	// rowOf returns the row of the metric
+	rowOf := func(c Comparison, metric string) ComparisonRow {
+		for _, v := range c.Rows {
+			if v.Metric == metric {
+				return v
+			}
+		}
+		t.Fatalf("no row for %s", metric)
+		return ComparisonRow{}
+	}
+
+	t.Run("identical runs", func(t *testing.T) {
+		c := CompareResults(baseline, []AggregatedResults{baseline}, 0, 0)
+		if c.Regressions != 0 {
+			t.Errorf("expected no regression, got %d", c.Regressions)
+		}
+	})
+
+	t.Run("regressions beyond the threshold", func(t *testing.T) {
+		run := baseline
+		run.AverageThroughput = 85 // -15%, worse
+		run.AverageLatency = 180   // -10%, better
+		run.TotalFails = 4         // +3 points, worse
+		run.TotalSuccess = 96
+
+		c := CompareResults(baseline, []AggregatedResults{run}, 10, 5)
+		if c.Regressions != 1 {
+			t.Errorf("expected 1 regression, got %d", c.Regressions)
+		}
+
+		throughput := rowOf(c, "Throughput [tx/s]").Changes[0]
+		if math.Abs(throughput.Change+15) > 1e-9 || !throughput.Regression {
+			t.Errorf("expected a throughput regression of -15%%, got %+v", throughput)
+		}
+
+		latency := rowOf(c, "Average latency [ms]").Changes[0]
+		if latency.Regression {
+			t.Errorf("a lower latency is not a regression: %+v", latency)
+		}
+
+		failures := rowOf(c, "Failure rate [%]").Changes[0]
+		if math.Abs(failures.Change-3) > 1e-9 || failures.Regression {
+			t.Errorf("expected a failure rate 3 points higher within the threshold, got %+v", failures)
+		}
+
+		// The relative threshold does not apply to the failure rate
+		c = CompareResults(baseline, []AggregatedResults{run}, 10, 1)
+		if failures := rowOf(c, "Failure rate [%]").Changes[0]; !failures.Regression || c.Regressions != 2 {
+			t.Errorf("expected a failure rate regression beyond 1 point, got %+v", failures)
+		}
+	})
+
+	t.Run("zero baseline", func(t *testing.T) {
+		zero := baseline
+		zero.AverageLatency = 0
+
+		c := CompareResults(zero, []AggregatedResults{baseline}, 10, 1)
+		if change := rowOf(c, "Average latency [ms]").Changes[0]; !math.IsInf(change.Change, 1) || !change.Regression {
+			t.Errorf("expected an infinite regression from a zero baseline, got %+v", change)
+		}
+	})
+}
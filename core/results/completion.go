@@ -0,0 +1,15 @@
+package results
+
+// CompletionReason is the criterion that ended a benchmark on a secondary
+type CompletionReason string
+
+// Criteria that end the benchmark
+const (
+	CompletionEmpty             CompletionReason = "empty"              // No transactions were sent
+	CompletionAllDone           CompletionReason = "all_done"           // All sent transactions are done
+	CompletionCommittedFraction CompletionReason = "committed_fraction" // The fraction of committed transactions was reached
+	CompletionIdle              CompletionReason = "idle"               // No transaction was done during the idle period
+	CompletionDeadline          CompletionReason = "deadline"           // The deadline from the start of the benchmark was reached
+	CompletionTimeout           CompletionReason = "timeout"            // The timeout after sending was reached
+	CompletionSpoolError        CompletionReason = "spool_error"        // The spooled workload could not be read, the rest was not sent
+)
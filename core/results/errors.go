@@ -0,0 +1,100 @@
+package results
+
+import "sort"
+
+// ErrorClass is the class of error a transaction failed with, the client
+// interfaces classify the errors of their blockchain into these classes.
+type ErrorClass string
+
+// Classes of transaction errors
+const (
+	ErrorNonceTooLow       ErrorClass = "nonce_too_low"
+	ErrorUnderpriced       ErrorClass = "underpriced"
+	ErrorTimeout           ErrorClass = "timeout"
+	ErrorEndorsement       ErrorClass = "endorsement_failure"
+	ErrorMVCCConflict      ErrorClass = "mvcc_conflict"
+	ErrorConnectionRefused ErrorClass = "connection_refused"
+	ErrorRevert            ErrorClass = "revert"
+	ErrorOther             ErrorClass = "other"
+)
+
+// IntervalErrors is the number of errors per class of an interval of the workload
+type IntervalErrors struct {
+	Interval int                 `json:"Interval"` // Interval of the workload
+	Errors   map[ErrorClass]uint `json:"Errors"`   // Number of errors per class
+}
+
+// CountErrors counts the errors per class of the given transaction records
+func CountErrors(records []TransactionRecord) map[ErrorClass]uint {
+	var counts map[ErrorClass]uint
+	for _, tx := range records {
+		if tx.Error == "" {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[ErrorClass]uint)
+		}
+		counts[tx.Error]++
+	}
+
+	return counts
+}
+
+// CalculateErrorBreakdown calculates the number of errors per class across all
+// secondaries and workers, both in total and per interval of the workload.
+func CalculateErrorBreakdown(res *AggregatedResults) {
+	totals := make(map[ErrorClass]uint)
+	perInterval := make(map[int]map[ErrorClass]uint)
+
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for class, count := range workerResult.Errors {
+				totals[class] += count
+			}
+
+			for _, tx := range workerResult.Transactions {
+				if tx.Error == "" {
+					continue
+				}
+				if _, ok := perInterval[tx.Interval]; !ok {
+					perInterval[tx.Interval] = make(map[ErrorClass]uint)
+				}
+				perInterval[tx.Interval][tx.Error]++
+			}
+		}
+	}
+
+	res.ErrorBreakdown = nil
+	if len(totals) > 0 {
+		res.ErrorBreakdown = totals
+	}
+
+	res.IntervalErrors = nil
+	for interval, counts := range perInterval {
+		res.IntervalErrors = append(res.IntervalErrors, IntervalErrors{
+			Interval: interval,
+			Errors:   counts,
+		})
+	}
+
+	sort.Slice(res.IntervalErrors, func(i, j int) bool {
+		return res.IntervalErrors[i].Interval < res.IntervalErrors[j].Interval
+	})
+}
+
+// sortedErrorClasses returns the classes of the breakdown, most frequent first
+func sortedErrorClasses(counts map[ErrorClass]uint) []ErrorClass {
+	classes := make([]ErrorClass, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+
+	sort.Slice(classes, func(i, j int) bool {
+		if counts[classes[i]] != counts[classes[j]] {
+			return counts[classes[i]] > counts[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+
+	return classes
+}
@@ -0,0 +1,311 @@
+package results
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is a format the results can be exported to, besides the JSON results
+type ExportFormat string
+
+// Formats the results can be exported to
+const (
+	ExportSummary   ExportFormat = "csv"       // Summary table with a row per secondary and a total row
+	ExportIntervals ExportFormat = "intervals" // Time series with a row per second of the benchmark
+	ExportTrace     ExportFormat = "trace"     // Trace with a row per transaction
+)
+
+// ExportFormats lists the formats the results can be exported to
+var ExportFormats = []ExportFormat{ExportSummary, ExportIntervals, ExportTrace}
+
+// ParseExportFormats parses a comma separated list of export formats
+func ParseExportFormats(list string) ([]ExportFormat, error) {
+	var formats []ExportFormat
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		known := false
+		for _, format := range ExportFormats {
+			if ExportFormat(v) == format {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown export format \"%s\"", v)
+		}
+
+		formats = append(formats, ExportFormat(v))
+	}
+
+	return formats, nil
+}
+
+// exportPath returns the path of the file of the export format
+func exportPath(prefix string, format ExportFormat) string {
+	switch format {
+	case ExportSummary:
+		return prefix + "_summary.csv"
+	case ExportIntervals:
+		return prefix + "_intervals.csv"
+	default:
+		return prefix + "_trace.csv"
+	}
+}
+
+// writeExports writes the results in each of the export formats, to files
+// named after the given prefix (directory and timestamp of the results)
+func writeExports(prefix string, results AggregatedResults, formats []ExportFormat) error {
+	for _, format := range formats {
+		var err error
+		switch format {
+		case ExportSummary:
+			err = writeCSV(exportPath(prefix, format), func(w *csv.Writer) error {
+				return writeSummaryCSV(w, results)
+			})
+		case ExportIntervals:
+			err = writeCSV(exportPath(prefix, format), func(w *csv.Writer) error {
+				return writeIntervalsCSV(w, results)
+			})
+		case ExportTrace:
+			err = writeCSV(exportPath(prefix, format), func(w *csv.Writer) error {
+				return writeTraceCSV(w, results)
+			})
+		default:
+			err = fmt.Errorf("unknown export format \"%s\"", format)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSV creates the file and writes the CSV rows with the given function
+func writeCSV(path string, write func(w *csv.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buffered := bufio.NewWriter(f)
+	w := csv.NewWriter(buffered)
+
+	if err := write(w); err != nil {
+		return err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return buffered.Flush()
+}
+
+// summaryRow returns the row of the summary table of the given results
+func summaryRow(name string, throughput float64, average float64, median float64, percentiles LatencyPercentiles, success uint, fail uint, completion CompletionReason) []string {
+	return []string{
+		name,
+		formatFloat(throughput),
+		formatFloat(average),
+		formatFloat(median),
+		formatFloat(percentiles.P75),
+		formatFloat(percentiles.P90),
+		formatFloat(percentiles.P95),
+		formatFloat(percentiles.P99),
+		formatFloat(percentiles.P999),
+		strconv.FormatUint(uint64(success), 10),
+		strconv.FormatUint(uint64(fail), 10),
+		string(completion),
+	}
+}
+
+// writeSummaryCSV writes a row of summary statistics per secondary and for all secondaries
+func writeSummaryCSV(w *csv.Writer, results AggregatedResults) error {
+	header := []string{
+		"secondary", "throughput", "avg_latency_ms", "median_latency_ms", "p75_latency_ms", "p90_latency_ms",
+		"p95_latency_ms", "p99_latency_ms", "p999_latency_ms", "success", "fail", "completion",
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for i, v := range results.SecondaryResults {
+		row := summaryRow(strconv.Itoa(i), v.Throughput, v.AverageLatency, v.MedianLatency, v.Percentiles, v.Success, v.Fail, v.CompletionReason)
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return w.Write(summaryRow("all", results.AverageThroughput, results.AverageLatency, results.MedianLatency, results.Percentiles, results.TotalSuccess, results.TotalFails, ""))
+}
+
+// intervalStats are the statistics of the transactions of one second of the benchmark
+type intervalStats struct {
+	scheduled uint    // Transactions scheduled in the second
+	sent      uint    // Transactions sent in the second
+	committed uint    // Transactions committed in the second
+	failed    uint    // Transactions scheduled in the second that failed
+	latency   float64 // Sum of the latencies from send of the transactions committed in the second [ms]
+	maxLag    float64 // Largest lag behind the schedule of the transactions sent in the second [ms]
+}
+
+// intervalSeries computes the statistics of each second of the benchmark, from
+// the earliest scheduled transaction, from the transaction records. It returns
+// the seconds holding transactions in order, nil if there are no records.
+func intervalSeries(results AggregatedResults) ([]int64, map[int64]*intervalStats) {
+	start := int64(-1)
+	for _, secondaryResult := range results.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Scheduled > 0 && (start < 0 || tx.Scheduled < start) {
+					start = tx.Scheduled
+				}
+			}
+		}
+	}
+
+	if start < 0 {
+		return nil, nil
+	}
+
+	stats := make(map[int64]*intervalStats)
+	get := func(t int64) *intervalStats {
+		second := (t - start) / 1e9
+		if _, ok := stats[second]; !ok {
+			stats[second] = &intervalStats{}
+		}
+		return stats[second]
+	}
+
+	for _, secondaryResult := range results.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Scheduled <= 0 {
+					continue
+				}
+
+				get(tx.Scheduled).scheduled++
+				if tx.Error != "" {
+					get(tx.Scheduled).failed++
+				}
+
+				if tx.Sent > 0 {
+					s := get(tx.Sent)
+					s.sent++
+					if lag := float64(tx.Sent-tx.Scheduled) / 1e6; lag > s.maxLag {
+						s.maxLag = lag
+					}
+				}
+
+				if tx.Committed > 0 && tx.Sent > 0 {
+					s := get(tx.Committed)
+					s.committed++
+					s.latency += float64(tx.Committed-tx.Sent) / 1e6
+				}
+			}
+		}
+	}
+
+	seconds := make([]int64, 0, len(stats))
+	for second := range stats {
+		seconds = append(seconds, second)
+	}
+	sort.Slice(seconds, func(i, j int) bool { return seconds[i] < seconds[j] })
+
+	return seconds, stats
+}
+
+// writeIntervalsCSV writes a row per second of the benchmark, from the
+// earliest scheduled transaction, computed from the transaction records
+func writeIntervalsCSV(w *csv.Writer, results AggregatedResults) error {
+	header := []string{"second", "scheduled", "sent", "committed", "failed", "avg_latency_ms", "max_lag_ms"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	seconds, stats := intervalSeries(results)
+
+	for _, second := range seconds {
+		s := stats[second]
+
+		averageLatency := float64(0)
+		if s.committed > 0 {
+			averageLatency = s.latency / float64(s.committed)
+		}
+
+		row := []string{
+			strconv.FormatInt(second, 10),
+			strconv.FormatUint(uint64(s.scheduled), 10),
+			strconv.FormatUint(uint64(s.sent), 10),
+			strconv.FormatUint(uint64(s.committed), 10),
+			strconv.FormatUint(uint64(s.failed), 10),
+			formatFloat(averageLatency),
+			formatFloat(s.maxLag),
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTraceCSV writes a row per transaction with its identifiers and timestamps
+func writeTraceCSV(w *csv.Writer, results AggregatedResults) error {
+	header := []string{
+		"secondary", "thread", "index", "id", "function", "node", "interval",
+		"scheduled_ns", "sent_ns", "committed_ns", "latency_ms", "error", "type",
+		"acknowledged_ns", "included_ns", "finalized_ns", "block",
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for secondaryID, secondaryResult := range results.RawResults {
+		for threadID, workerResult := range secondaryResult {
+			for i, tx := range workerResult.Transactions {
+				latency := ""
+				if tx.Committed > 0 && tx.Sent > 0 {
+					latency = formatFloat(float64(tx.Committed-tx.Sent) / 1e6)
+				}
+
+				row := []string{
+					strconv.Itoa(secondaryID),
+					strconv.Itoa(threadID),
+					strconv.Itoa(i),
+					tx.ID,
+					tx.Function,
+					workerResult.Node,
+					strconv.Itoa(tx.Interval),
+					strconv.FormatInt(tx.Scheduled, 10),
+					strconv.FormatInt(tx.Sent, 10),
+					strconv.FormatInt(tx.Committed, 10),
+					latency,
+					string(tx.Error),
+					tx.Type,
+					strconv.FormatInt(tx.Acknowledged, 10),
+					strconv.FormatInt(tx.Included, 10),
+					strconv.FormatInt(tx.Finalized, 10),
+					strconv.FormatUint(tx.Block, 10),
+				}
+				if err := w.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package results
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readCSV reads all the rows of the CSV file
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rows
+}
+
+func TestExports(t *testing.T) {
+	s := int64(time.Second)
+	ms := int64(time.Millisecond)
+
+	res := CalculateAggregatedResults([][]Results{{{
+		TxLatencies:       []float64{100, 200},
+		ThroughputSeconds: []float64{2},
+		Success:           2,
+		Fail:              1,
+		Node:              "127.0.0.1:8545",
+		Transactions: []TransactionRecord{
+			{ID: "0xa", Interval: 0, Scheduled: 10 * s, Sent: 10 * s, Committed: 10*s + 100*ms},
+			{ID: "0xb", Function: "0x12345678", Interval: 0, Scheduled: 10*s + 500*ms, Sent: 11 * s, Committed: 11*s + 200*ms},
+			{ID: "0xc", Interval: 1, Scheduled: 11 * s, Sent: 11 * s, Error: ErrorRevert},
+		},
+	}}})
+
+	t.Run("parse formats", func(t *testing.T) {
+		formats, err := ParseExportFormats("csv, trace")
+		if err != nil || len(formats) != 2 || formats[1] != ExportTrace {
+			t.Errorf("unexpected formats %v (%v)", formats, err)
+		}
+
+		if _, err := ParseExportFormats("csv,xml"); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+
+	prefix := filepath.Join(t.TempDir(), "run")
+	if err := writeExports(prefix, res, ExportFormats); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("summary", func(t *testing.T) {
+		rows := readCSV(t, exportPath(prefix, ExportSummary))
+		if len(rows) != 3 || rows[1][0] != "0" || rows[2][0] != "all" {
+			t.Fatalf("expected a header, a secondary and a total row, got %v", rows)
+		}
+		if rows[2][3] != "150.000" || rows[2][10] != "1" {
+			t.Errorf("expected median 150.000 and 1 failure, got %s and %s", rows[2][3], rows[2][10])
+		}
+	})
+
+	t.Run("intervals", func(t *testing.T) {
+		rows := readCSV(t, exportPath(prefix, ExportIntervals))
+		expected := [][]string{
+			{"second", "scheduled", "sent", "committed", "failed", "avg_latency_ms", "max_lag_ms"},
+			{"0", "2", "1", "1", "0", "100.000", "0.000"},
+			{"1", "1", "2", "1", "1", "200.000", "500.000"},
+		}
+		if len(rows) != len(expected) {
+			t.Fatalf("expected %d rows, got %v", len(expected), rows)
+		}
+		for i := range expected {
+			for j := range expected[i] {
+				if rows[i][j] != expected[i][j] {
+					t.Errorf("row %d column %s: expected %s, got %s", i, expected[0][j], expected[i][j], rows[i][j])
+				}
+			}
+		}
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		rows := readCSV(t, exportPath(prefix, ExportTrace))
+		if len(rows) != 4 {
+			t.Fatalf("expected a header and 3 transactions, got %d rows", len(rows))
+		}
+		if rows[2][3] != "0xb" || rows[2][4] != "0x12345678" || rows[2][5] != "127.0.0.1:8545" || rows[2][10] != "200.000" {
+			t.Errorf("unexpected trace row %v", rows[2])
+		}
+		if rows[3][10] != "" || rows[3][11] != string(ErrorRevert) {
+			t.Errorf("unexpected trace row of the failed transaction %v", rows[3])
+		}
+	})
+}
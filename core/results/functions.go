@@ -0,0 +1,175 @@
+package results
+
+import (
+	"sort"
+	"time"
+)
+
+// Types of the functions called by the transactions
+const (
+	FunctionTypeRead  = "read"  // Function that queries the state
+	FunctionTypeWrite = "write" // Function that submits a state change
+)
+
+// transferFunction is the name of the breakdown of the transactions that call
+// no function (transfers)
+const transferFunction = "transfer"
+
+// FunctionInfo describes the function called by transactions, as defined in the
+// benchmark configuration
+type FunctionInfo struct {
+	Name string // Name of the function
+	Type string // Type of the function, "read" or "write"
+}
+
+// BreakdownResults are the results of the transactions calling one function,
+// or one type of functions
+type BreakdownResults struct {
+	Name           string              `json:"Name"`             // Name of the function or type
+	Transactions   uint                `json:"Transactions"`     // Number of transactions sent
+	Success        uint                `json:"Success"`          // Number of transactions committed
+	Fails          uint                `json:"Fails"`            // Number of transactions that failed
+	Throughput     float64             `json:"Throughput"`       // Committed transactions per second over the benchmark [tx/sec]
+	AverageLatency float64             `json:"AverageLatency"`   // Average latency of the committed transactions [ms]
+	Percentiles    LatencyPercentiles  `json:"Percentiles"`      // Percentiles of the latencies of the committed transactions
+	Errors         map[ErrorClass]uint `json:"Errors,omitempty"` // Number of errors per class
+}
+
+// ResolveFunctions resolves the function identifiers recorded by the client
+// interfaces (e.g. function selectors) into the functions of the benchmark
+// configuration, naming the function and setting its type if the client did
+// not record it. Unknown identifiers are kept as is.
+func ResolveFunctions(res *AggregatedResults, functions map[string]FunctionInfo) {
+	if len(functions) == 0 {
+		return
+	}
+
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for i := range workerResult.Transactions {
+				tx := &workerResult.Transactions[i]
+
+				f, ok := functions[tx.Function]
+				if !ok {
+					continue
+				}
+				tx.Function = f.Name
+				if tx.Type == "" {
+					tx.Type = f.Type
+				}
+			}
+		}
+	}
+}
+
+// breakdown accumulates the results of a group of transactions
+type breakdown struct {
+	results   BreakdownResults
+	latencies []float64
+}
+
+// add adds the transaction to the group
+func (b *breakdown) add(tx TransactionRecord) {
+	b.results.Transactions++
+
+	if tx.Error != "" {
+		b.results.Fails++
+		if b.results.Errors == nil {
+			b.results.Errors = make(map[ErrorClass]uint)
+		}
+		b.results.Errors[tx.Error]++
+		return
+	}
+
+	if tx.Committed > 0 {
+		b.results.Success++
+		b.latencies = append(b.latencies, float64(tx.Committed-tx.Sent)/float64(time.Millisecond))
+	}
+}
+
+// finish computes the throughput and latencies of the group over the given
+// duration of the benchmark
+func (b *breakdown) finish(duration time.Duration) BreakdownResults {
+	if duration > 0 {
+		b.results.Throughput = float64(b.results.Success) / duration.Seconds()
+	}
+
+	if len(b.latencies) > 0 {
+		sort.Float64s(b.latencies)
+
+		sum := float64(0)
+		for _, v := range b.latencies {
+			sum += v
+		}
+		b.results.AverageLatency = sum / float64(len(b.latencies))
+		b.results.Percentiles = percentilesOf(b.latencies)
+	}
+
+	return b.results
+}
+
+// finishBreakdowns returns the results of the groups sorted by name
+func finishBreakdowns(groups map[string]*breakdown, duration time.Duration) []BreakdownResults {
+	var breakdowns []BreakdownResults
+	for _, b := range groups {
+		breakdowns = append(breakdowns, b.finish(duration))
+	}
+
+	sort.Slice(breakdowns, func(i, j int) bool {
+		return breakdowns[i].Name < breakdowns[j].Name
+	})
+
+	return breakdowns
+}
+
+// CalculateFunctionBreakdown calculates the throughput, latencies and failures
+// of the transactions per function and per type of function (read or write),
+// from the transaction records of all secondaries. The throughput of each group
+// is over the whole benchmark, from the first send to the last commit.
+// Transactions of an unknown type are only counted per function.
+func CalculateFunctionBreakdown(res *AggregatedResults) {
+	functions := make(map[string]*breakdown)
+	types := make(map[string]*breakdown)
+
+	start, end := int64(-1), int64(-1)
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Sent == 0 {
+					continue
+				}
+				if start < 0 || tx.Sent < start {
+					start = tx.Sent
+				}
+				if tx.Committed > end {
+					end = tx.Committed
+				}
+
+				name := tx.Function
+				if name == "" {
+					name = transferFunction
+				}
+				if _, ok := functions[name]; !ok {
+					functions[name] = &breakdown{results: BreakdownResults{Name: name}}
+				}
+				functions[name].add(tx)
+
+				if tx.Type == "" {
+					continue
+				}
+				if _, ok := types[tx.Type]; !ok {
+					types[tx.Type] = &breakdown{results: BreakdownResults{Name: tx.Type}}
+				}
+				types[tx.Type].add(tx)
+			}
+		}
+	}
+
+	var duration time.Duration
+	if end > start {
+		duration = time.Duration(end - start)
+	}
+
+	res.FunctionBreakdown = finishBreakdowns(functions, duration)
+	res.TypeBreakdown = finishBreakdowns(types, duration)
+}
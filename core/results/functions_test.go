@@ -0,0 +1,70 @@
+package results
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateFunctionBreakdown(t *testing.T) {
+	s := int64(time.Second)
+	ms := int64(time.Millisecond)
+
+	res := CalculateAggregatedResults([][]Results{
+		{{
+			Transactions: []TransactionRecord{
+				{Function: "0xa9059cbb", Sent: s, Committed: s + 100*ms},
+				{Function: "0xa9059cbb", Sent: s, Committed: s + 300*ms},
+				{Function: "0x70a08231", Sent: s, Committed: s + 50*ms},
+				{Function: "0xa9059cbb", Sent: s, Error: ErrorRevert},
+			},
+		}},
+		{{
+			Transactions: []TransactionRecord{
+				{Function: "query", Type: FunctionTypeRead, Sent: s, Committed: 3 * s},
+				{Sent: s, Committed: s + 200*ms},
+			},
+		}},
+	})
+
+	ResolveFunctions(&res, map[string]FunctionInfo{
+		"0xa9059cbb": {Name: "transfer(address,uint256)", Type: FunctionTypeWrite},
+		"0x70a08231": {Name: "balanceOf(address)", Type: FunctionTypeRead},
+	})
+	CalculateFunctionBreakdown(&res)
+
+	t.Run("per function", func(t *testing.T) {
+		expected := []string{"balanceOf(address)", "query", "transfer", "transfer(address,uint256)"}
+		if len(res.FunctionBreakdown) != len(expected) {
+			t.Fatalf("expected %d functions, got %+v", len(expected), res.FunctionBreakdown)
+		}
+		for i, name := range expected {
+			if res.FunctionBreakdown[i].Name != name {
+				t.Errorf("function %d: expected %s, got %s", i, name, res.FunctionBreakdown[i].Name)
+			}
+		}
+
+		v := res.FunctionBreakdown[3]
+		if v.Transactions != 3 || v.Success != 2 || v.Fails != 1 || v.Errors[ErrorRevert] != 1 {
+			t.Errorf("unexpected counts %+v", v)
+		}
+		if v.AverageLatency != 200 || v.Percentiles.P99 != 300 {
+			t.Errorf("unexpected latencies %+v", v)
+		}
+		// 2 committed over the 2 seconds from the first send to the last commit
+		if v.Throughput != 1 {
+			t.Errorf("expected 1 tx/sec, got %v", v.Throughput)
+		}
+	})
+
+	t.Run("per type", func(t *testing.T) {
+		if len(res.TypeBreakdown) != 2 {
+			t.Fatalf("expected 2 types, got %+v", res.TypeBreakdown)
+		}
+		if read := res.TypeBreakdown[0]; read.Name != FunctionTypeRead || read.Transactions != 2 || read.Success != 2 {
+			t.Errorf("unexpected reads %+v", read)
+		}
+		if write := res.TypeBreakdown[1]; write.Name != FunctionTypeWrite || write.Transactions != 3 || write.Fails != 1 {
+			t.Errorf("unexpected writes %+v", write)
+		}
+	})
+}
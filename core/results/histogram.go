@@ -0,0 +1,218 @@
+package results
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// histogramSubBits is the number of bits of precision of the histogram
+// buckets: values are recorded with a relative error below 2^-(histogramSubBits-1),
+// about 0.1%.
+const histogramSubBits = 11
+
+// histogramUnit is the number of recorded units per millisecond, latencies are
+// recorded with a microsecond resolution.
+const histogramUnit = 1000
+
+// LatencyPercentiles are the percentiles of the latencies in milliseconds
+type LatencyPercentiles struct {
+	P50  float64 `json:"P50"`  // 50th percentile (median)
+	P75  float64 `json:"P75"`  // 75th percentile
+	P90  float64 `json:"P90"`  // 90th percentile
+	P95  float64 `json:"P95"`  // 95th percentile
+	P99  float64 `json:"P99"`  // 99th percentile
+	P999 float64 `json:"P999"` // 99.9th percentile
+}
+
+// LatencyHistogram is a compact, mergeable histogram of latencies with
+// log-linear buckets in the manner of HDR histograms. Only the buckets holding
+// values are stored, histograms of different workers merge by adding the counts.
+type LatencyHistogram struct {
+	Counts map[int]uint64 `json:"Counts"` // Number of values in each bucket
+	Count  uint64         `json:"Count"`  // Number of values recorded
+	Sum    float64        `json:"Sum"`    // Sum of the values recorded (ms)
+	Min    float64        `json:"Min"`    // Smallest value recorded (ms)
+	Max    float64        `json:"Max"`    // Largest value recorded (ms)
+}
+
+// NewLatencyHistogram creates a histogram of the given latencies (ms)
+func NewLatencyHistogram(latencies []float64) *LatencyHistogram {
+	h := &LatencyHistogram{Counts: make(map[int]uint64)}
+	for _, v := range latencies {
+		h.Record(v)
+	}
+
+	return h
+}
+
+// histogramBucket returns the bucket of the value: values below 2^histogramSubBits
+// have a bucket each, larger values share buckets of the same relative width.
+func histogramBucket(value uint64) int {
+	shift := bits.Len64(value) - histogramSubBits
+	if shift <= 0 {
+		return int(value)
+	}
+
+	return shift<<(histogramSubBits-1) + int(value>>uint(shift))
+}
+
+// histogramBucketRange returns the lowest and highest value of the bucket
+func histogramBucketRange(bucket int) (uint64, uint64) {
+	if bucket < 1<<histogramSubBits {
+		return uint64(bucket), uint64(bucket)
+	}
+
+	shift := bucket>>(histogramSubBits-1) - 1
+	lowest := uint64(bucket-shift<<(histogramSubBits-1)) << uint(shift)
+
+	return lowest, lowest + 1<<uint(shift) - 1
+}
+
+// Record adds a latency (ms) to the histogram, negative latencies are recorded as 0
+func (h *LatencyHistogram) Record(latency float64) {
+	if latency < 0 {
+		latency = 0
+	}
+
+	if h.Counts == nil {
+		h.Counts = make(map[int]uint64)
+	}
+	h.Counts[histogramBucket(uint64(math.Round(latency*histogramUnit)))]++
+
+	if h.Count == 0 || latency < h.Min {
+		h.Min = latency
+	}
+	if latency > h.Max {
+		h.Max = latency
+	}
+	h.Count++
+	h.Sum += latency
+}
+
+// Merge adds the values of the other histogram to this one
+func (h *LatencyHistogram) Merge(other *LatencyHistogram) {
+	if other == nil || other.Count == 0 {
+		return
+	}
+
+	if h.Counts == nil {
+		h.Counts = make(map[int]uint64)
+	}
+	for bucket, count := range other.Counts {
+		h.Counts[bucket] += count
+	}
+
+	if h.Count == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+	h.Count += other.Count
+	h.Sum += other.Sum
+}
+
+// Mean returns the mean of the values recorded, 0 if empty
+func (h *LatencyHistogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return h.Sum / float64(h.Count)
+}
+
+// Percentile returns the given percentile (0 - 100) of the values recorded
+// using the nearest-rank method, as the middle of the bucket of that rank.
+func (h *LatencyHistogram) Percentile(percentile float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(percentile / 100 * float64(h.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	buckets := make([]int, 0, len(h.Counts))
+	for bucket := range h.Counts {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	seen := uint64(0)
+	for _, bucket := range buckets {
+		seen += h.Counts[bucket]
+		if seen >= rank {
+			lowest, highest := histogramBucketRange(bucket)
+			value := float64(lowest+highest) / 2 / histogramUnit
+
+			// The smallest and largest values are known exactly
+			return math.Min(math.Max(value, h.Min), h.Max)
+		}
+	}
+
+	return h.Max
+}
+
+// Percentiles returns the reported percentiles of the values recorded
+func (h *LatencyHistogram) Percentiles() LatencyPercentiles {
+	return LatencyPercentiles{
+		P50:  h.Percentile(50),
+		P75:  h.Percentile(75),
+		P90:  h.Percentile(90),
+		P95:  h.Percentile(95),
+		P99:  h.Percentile(99),
+		P999: h.Percentile(99.9),
+	}
+}
+
+// percentilesOf returns the reported percentiles of the sorted latencies
+func percentilesOf(sortedLatencies []float64) LatencyPercentiles {
+	return LatencyPercentiles{
+		P50:  getPercentile(sortedLatencies, 50),
+		P75:  getPercentile(sortedLatencies, 75),
+		P90:  getPercentile(sortedLatencies, 90),
+		P95:  getPercentile(sortedLatencies, 95),
+		P99:  getPercentile(sortedLatencies, 99),
+		P999: getPercentile(sortedLatencies, 99.9),
+	}
+}
+
+// latencyHistogram returns the histogram of the latencies of the worker,
+// built from the raw latencies if the worker did not return one.
+func (r *Results) latencyHistogram() *LatencyHistogram {
+	if r.Histogram != nil {
+		return r.Histogram
+	}
+
+	return NewLatencyHistogram(r.TxLatencies)
+}
+
+// hasRawLatencies returns true if the worker returned the latency of every transaction
+func (r *Results) hasRawLatencies() bool {
+	return r.Histogram == nil || uint64(len(r.TxLatencies)) == r.Histogram.Count
+}
+
+// LatencyPercentile returns the given percentile (0 - 100) of the latencies
+// of all transactions, exact if the latencies of all transactions are known and
+// from the merged histogram otherwise.
+func LatencyPercentile(res *AggregatedResults, percentile float64) float64 {
+	if len(res.AllTxLatencies) > 0 || res.Histogram == nil {
+		sortedLatencies := make([]float64, len(res.AllTxLatencies))
+		copy(sortedLatencies, res.AllTxLatencies)
+		sort.Float64s(sortedLatencies)
+		return getPercentile(sortedLatencies, percentile)
+	}
+
+	return res.Histogram.Percentile(percentile)
+}
+
+// numLatencies returns the number of transaction latencies of the results
+func (res *AggregatedResults) numLatencies() uint64 {
+	if len(res.AllTxLatencies) > 0 || res.Histogram == nil {
+		return uint64(len(res.AllTxLatencies))
+	}
+
+	return res.Histogram.Count
+}
@@ -0,0 +1,120 @@
+package results
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	t.Run("buckets cover their values", func(t *testing.T) {
+		for _, v := range []uint64{0, 1, 2047, 2048, 2049, 4095, 4096, 123456, 987654321} {
+			lowest, highest := histogramBucketRange(histogramBucket(v))
+			if v < lowest || v > highest {
+				t.Errorf("value %d outside of its bucket [%d, %d]", v, lowest, highest)
+			}
+			if float64(highest-lowest) > float64(v)/1000 {
+				t.Errorf("bucket [%d, %d] of value %d is too wide", lowest, highest, v)
+			}
+		}
+	})
+
+	t.Run("percentiles within the precision", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		latencies := make([]float64, 10000)
+		for i := range latencies {
+			latencies[i] = rng.ExpFloat64() * 250
+		}
+
+		h := NewLatencyHistogram(latencies)
+		sort.Float64s(latencies)
+
+		exact := percentilesOf(latencies)
+		approx := h.Percentiles()
+		for _, v := range [][2]float64{
+			{exact.P50, approx.P50}, {exact.P90, approx.P90}, {exact.P99, approx.P99}, {exact.P999, approx.P999},
+		} {
+			if math.Abs(v[0]-v[1]) > v[0]*0.001+0.001 {
+				t.Errorf("expected percentile %.3f, got %.3f", v[0], v[1])
+			}
+		}
+
+		if h.Max != latencies[len(latencies)-1] || h.Min != latencies[0] {
+			t.Errorf("expected exact min and max, got %.3f and %.3f", h.Min, h.Max)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		a := NewLatencyHistogram([]float64{1, 2, 3})
+		b := NewLatencyHistogram([]float64{10, 20})
+
+		// Histograms are merged after going through the results encoding
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded LatencyHistogram
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		a.Merge(&decoded)
+		whole := NewLatencyHistogram([]float64{1, 2, 3, 10, 20})
+
+		if a.Count != whole.Count || a.Sum != whole.Sum || a.Min != whole.Min || a.Max != whole.Max {
+			t.Errorf("merged histogram %+v differs from %+v", a, whole)
+		}
+		if a.Percentile(80) != whole.Percentile(80) {
+			t.Errorf("expected p80 %.3f, got %.3f", whole.Percentile(80), a.Percentile(80))
+		}
+	})
+}
+
+func TestAggregatedPercentiles(t *testing.T) {
+	secondaries := [][]Results{
+		{{TxLatencies: []float64{1, 1, 1}}},
+		{{TxLatencies: []float64{100}}},
+	}
+
+	t.Run("median over all transactions", func(t *testing.T) {
+		res := CalculateAggregatedResults(secondaries)
+
+		if res.MedianLatency != 1 {
+			t.Errorf("expected median 1, got %.3f", res.MedianLatency)
+		}
+		if res.AverageLatency != 25.75 {
+			t.Errorf("expected average 25.75, got %.3f", res.AverageLatency)
+		}
+		if res.Percentiles.P99 != 100 {
+			t.Errorf("expected p99 100, got %.3f", res.Percentiles.P99)
+		}
+	})
+
+	t.Run("histograms only", func(t *testing.T) {
+		var histogramOnly [][]Results
+		for _, secondary := range secondaries {
+			var workers []Results
+			for _, worker := range secondary {
+				workers = append(workers, Results{
+					Histogram: NewLatencyHistogram(worker.TxLatencies),
+				})
+			}
+			histogramOnly = append(histogramOnly, workers)
+		}
+
+		res := CalculateAggregatedResults(histogramOnly)
+
+		if len(res.AllTxLatencies) != 0 {
+			t.Errorf("expected no raw latencies, got %d", len(res.AllTxLatencies))
+		}
+		// Within the precision of the histogram
+		if res.MedianLatency != 1 || math.Abs(LatencyPercentile(&res, 99)-100) > 0.1 {
+			t.Errorf("expected median 1 and p99 100, got %.3f and %.3f", res.MedianLatency, LatencyPercentile(&res, 99))
+		}
+		if res.MinLatency != 1 || res.MaxLatency != 100 || res.AverageLatency != 25.75 {
+			t.Errorf("expected min 1, max 100 and average 25.75, got %.3f, %.3f and %.3f", res.MinLatency, res.MaxLatency, res.AverageLatency)
+		}
+	})
+}
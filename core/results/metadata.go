@@ -0,0 +1,199 @@
+package results
+
+import (
+	"crypto/sha256"
+	"diablo-benchmark/core/configs"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SchemaVersion is the version of the JSON schema of the results written by
+// this version of Diablo. Version 1 is the schema of the results written
+// before the metadata was added, which carry no version.
+const SchemaVersion = 3
+
+// Version is the version of Diablo, set at build time with
+// -ldflags "-X diablo-benchmark/core/results.Version=<version>"
+// and otherwise taken from the module information of the binary.
+var Version = ""
+
+// Metadata describes the run that produced the results, so that they can be
+// interpreted after the code or configurations changed
+type Metadata struct {
+	SchemaVersion   int                 `json:"SchemaVersion"`         // Version of the schema of the results
+	RunID           string              `json:"RunID"`                 // Identifier of the run (timestamp of the start of the primary, then the number of the sweep combination)
+	Start           time.Time           `json:"Start"`                 // Start of the benchmark
+	End             time.Time           `json:"End"`                   // End of the benchmark
+	DiabloVersion   string              `json:"DiabloVersion"`         // Version of Diablo that ran the benchmark
+	Host            string              `json:"Host"`                  // Host name of the primary
+	BenchConfigHash string              `json:"BenchConfigHash"`       // SHA-256 of the benchmark configuration file
+	ChainConfigHash string              `json:"ChainConfigHash"`       // SHA-256 of the chain configuration file
+	Sweep           *configs.SweepPoint `json:"Sweep,omitempty"`       // Parameters of the sweep combination run from the benchmark configuration
+	Secondaries     []SecondaryInfo     `json:"Secondaries,omitempty"` // Secondaries that ran the benchmark
+}
+
+// SecondaryInfo describes a secondary that ran the benchmark
+type SecondaryInfo struct {
+	ID      int      `json:"ID"`              // ID of the secondary
+	Address string   `json:"Address"`         // Address of the secondary seen by the primary
+	Host    string   `json:"Host,omitempty"`  // Host name reported by the secondary
+	Threads int      `json:"Threads"`         // Number of workers of the secondary
+	Nodes   []string `json:"Nodes,omitempty"` // Blockchain nodes the workers sent their transactions to
+}
+
+// diabloVersion returns the version of Diablo running
+func diabloVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "unknown"
+}
+
+// hashFile returns the hex SHA-256 of the content of the file
+func hashFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
+
+// NewMetadata returns the metadata of the benchmark run by this primary
+// between the given times with the given configuration files
+func NewMetadata(runID string, start time.Time, end time.Time, benchConfigPath string, chainConfigPath string) Metadata {
+	m := Metadata{
+		SchemaVersion: SchemaVersion,
+		RunID:         runID,
+		Start:         start,
+		End:           end,
+		DiabloVersion: diabloVersion(),
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		m.Host = host
+	}
+
+	var err error
+	if m.BenchConfigHash, err = hashFile(benchConfigPath); err != nil {
+		zap.L().Warn("failed to hash the benchmark configuration",
+			zap.Error(err))
+	}
+	if m.ChainConfigHash, err = hashFile(chainConfigPath); err != nil {
+		zap.L().Warn("failed to hash the chain configuration",
+			zap.Error(err))
+	}
+
+	return m
+}
+
+// AddSecondaries adds the information of the secondaries, from their address
+// and the results of their workers
+func (m *Metadata) AddSecondaries(addresses []string, rawResults [][]Results) {
+	for i, address := range addresses {
+		info := SecondaryInfo{ID: i, Address: address}
+
+		if i < len(rawResults) {
+			info.Threads = len(rawResults[i])
+
+			seen := make(map[string]bool)
+			for _, v := range rawResults[i] {
+				if v.Host != "" {
+					info.Host = v.Host
+				}
+				if v.Node != "" && !seen[v.Node] {
+					seen[v.Node] = true
+					info.Nodes = append(info.Nodes, v.Node)
+				}
+			}
+		}
+
+		m.Secondaries = append(m.Secondaries, info)
+	}
+}
+
+// resultsUpgrades upgrade the results from the schema version (key) to the next one
+var resultsUpgrades = map[int]func(res *AggregatedResults){
+	1: upgradeResultsV1,
+	2: upgradeResultsV2,
+}
+
+// upgradeResultsV1 upgrades results without metadata, which may predate the
+// latency percentiles and histograms, computing them from the latencies
+func upgradeResultsV1(res *AggregatedResults) {
+	for i := range res.SecondaryResults {
+		v := &res.SecondaryResults[i]
+		if v.Histogram == nil && len(v.TxLatencies) > 0 {
+			v.Histogram = NewLatencyHistogram(v.TxLatencies)
+			v.Percentiles = v.Histogram.Percentiles()
+		}
+	}
+
+	if res.Histogram == nil && len(res.AllTxLatencies) > 0 {
+		res.Histogram = NewLatencyHistogram(res.AllTxLatencies)
+
+		sortedLatencies := make([]float64, len(res.AllTxLatencies))
+		copy(sortedLatencies, res.AllTxLatencies)
+		sort.Float64s(sortedLatencies)
+		res.Percentiles = percentilesOf(sortedLatencies)
+	}
+}
+
+// upgradeResultsV2 upgrades results whose throughput over time was measured
+// by the tickers of the workers and summed by index. It cannot be realigned, so
+// it is kept as is with no throughput window.
+func upgradeResultsV2(res *AggregatedResults) {
+	res.ThroughputWindow = 0
+	res.ThroughputStart = 0
+}
+
+// UpgradeResults upgrades results of an older schema version to the current one
+func UpgradeResults(res *AggregatedResults) error {
+	version := res.Metadata.SchemaVersion
+	if version == 0 {
+		version = 1
+	}
+
+	if version > SchemaVersion {
+		return fmt.Errorf("results of schema version %d are newer than the supported version %d", version, SchemaVersion)
+	}
+
+	for ; version < SchemaVersion; version++ {
+		resultsUpgrades[version](res)
+	}
+	res.Metadata.SchemaVersion = SchemaVersion
+
+	return nil
+}
+
+// LoadResults reads the aggregated results from a results file, upgrading
+// results written by older versions of Diablo to the current schema
+func LoadResults(path string) (AggregatedResults, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return AggregatedResults{}, err
+	}
+
+	var results AggregatedResults
+	if err = json.Unmarshal(content, &results); err != nil {
+		return AggregatedResults{}, fmt.Errorf("failed to parse results %s: %s", path, err.Error())
+	}
+
+	if err = UpgradeResults(&results); err != nil {
+		return AggregatedResults{}, fmt.Errorf("failed to load results %s: %s", path, err.Error())
+	}
+
+	return results, nil
+}
@@ -0,0 +1,84 @@
+package results
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	dir := t.TempDir()
+	benchConfig := filepath.Join(dir, "bench.yaml")
+	if err := ioutil.WriteFile(benchConfig, []byte("name: sample\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("new metadata", func(t *testing.T) {
+		start := time.Now()
+		m := NewMetadata("run", start, start.Add(time.Minute), benchConfig, filepath.Join(dir, "missing.yaml"))
+
+		if m.SchemaVersion != SchemaVersion || m.RunID != "run" || m.DiabloVersion == "" {
+			t.Errorf("unexpected metadata %+v", m)
+		}
+		if len(m.BenchConfigHash) != 64 {
+			t.Errorf("expected the hash of the benchmark configuration, got %q", m.BenchConfigHash)
+		}
+		if m.ChainConfigHash != "" {
+			t.Errorf("expected no hash of a missing configuration, got %q", m.ChainConfigHash)
+		}
+	})
+
+	t.Run("secondaries", func(t *testing.T) {
+		var m Metadata
+		m.AddSecondaries([]string{"10.0.0.1:4000", "10.0.0.2:4000"}, [][]Results{
+			{{Host: "a", Node: "n1"}, {Host: "a", Node: "n2"}, {Host: "a", Node: "n1"}},
+			{{Host: "b", Node: "n3"}},
+		})
+
+		if len(m.Secondaries) != 2 {
+			t.Fatalf("expected 2 secondaries, got %d", len(m.Secondaries))
+		}
+		if s := m.Secondaries[0]; s.Host != "a" || s.Threads != 3 || len(s.Nodes) != 2 {
+			t.Errorf("unexpected secondary %+v", s)
+		}
+	})
+}
+
+func TestLoadResults(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("results without a schema version", func(t *testing.T) {
+		path := filepath.Join(dir, "v1_results.json")
+		content := `{"AllTxLatencies": [10, 20, 30, 40], "SecondaryResults": [{"TxLatencies": [10, 20]}], "AverageThroughput": 5}`
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := LoadResults(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Metadata.SchemaVersion != SchemaVersion {
+			t.Errorf("expected the results upgraded to version %d, got %d", SchemaVersion, res.Metadata.SchemaVersion)
+		}
+		if res.Percentiles.P50 != 20 || res.Histogram == nil || res.Histogram.Count != 4 {
+			t.Errorf("expected the percentiles computed from the latencies, got %+v", res.Percentiles)
+		}
+		if res.SecondaryResults[0].Histogram == nil {
+			t.Error("expected the histogram of the secondary computed from its latencies")
+		}
+	})
+
+	t.Run("newer schema version", func(t *testing.T) {
+		path := filepath.Join(dir, "future_results.json")
+		if err := ioutil.WriteFile(path, []byte(`{"Metadata": {"SchemaVersion": 1000}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadResults(path); err == nil {
+			t.Error("expected an error for results of a newer schema version")
+		}
+	})
+}
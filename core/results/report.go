@@ -0,0 +1,615 @@
+package results
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dimensions of the charts of the report [px]
+const (
+	chartWidth  = 720
+	chartHeight = 320
+	chartLeft   = 64
+	chartRight  = 24
+	chartTop    = 36
+	chartBottom = 48
+	chartTicks  = 5
+)
+
+// maxScatterPoints is the largest number of transactions drawn in the scatter
+// plot, the transactions are sampled evenly beyond it to keep the report small
+const maxScatterPoints = 5000
+
+// maxCDFPoints is the largest number of points of the latency CDF
+const maxCDFPoints = 500
+
+// point is a point of a chart
+type point struct {
+	x float64
+	y float64
+}
+
+// chart draws the frame of an SVG chart: the title, the axes with their ticks
+// and labels. The axes start at 0, categories replace the ticks of the x axis.
+type chart struct {
+	title      string   // Title of the chart
+	xLabel     string   // Label of the x axis
+	yLabel     string   // Label of the y axis
+	xMax       float64  // Largest value of the x axis
+	yMax       float64  // Largest value of the y axis
+	categories []string // Labels of the categories of the x axis (bar charts)
+}
+
+// niceStep returns a round step (1, 2 or 5 times a power of 10) to divide the
+// range into about the given number of ticks
+func niceStep(max float64, ticks int) float64 {
+	if max <= 0 {
+		return 1
+	}
+
+	raw := max / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, v := range []float64{1, 2, 5, 10} {
+		if raw <= v*magnitude {
+			return v * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+// niceMax returns the end of the axis holding the value, a multiple of its step
+func niceMax(max float64) float64 {
+	step := niceStep(max, chartTicks)
+	return math.Max(step, math.Ceil(max/step)*step)
+}
+
+// x returns the horizontal coordinate of the value
+func (c chart) x(v float64) float64 {
+	return chartLeft + v/c.xMax*(chartWidth-chartLeft-chartRight)
+}
+
+// y returns the vertical coordinate of the value
+func (c chart) y(v float64) float64 {
+	return chartHeight - chartBottom - v/c.yMax*(chartHeight-chartTop-chartBottom)
+}
+
+// open writes the start of the SVG chart with its frame
+func (c chart) open(b *strings.Builder) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`,
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(b, `<text x="%d" y="20" font-size="14" font-weight="bold">%s</text>`, chartLeft, html.EscapeString(c.title))
+
+	left, right := c.x(0), c.x(c.xMax)
+	top, bottom := c.y(c.yMax), c.y(0)
+
+	// Horizontal grid lines with the ticks of the y axis
+	step := niceStep(c.yMax, chartTicks)
+	for v := float64(0); v <= c.yMax+step/2; v += step {
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`, left, c.y(v), right, c.y(v))
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end">%s</text>`, left-6, c.y(v)+4, formatTick(v))
+	}
+
+	if len(c.categories) > 0 {
+		width := (right - left) / float64(len(c.categories))
+		for i, v := range c.categories {
+			fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, left+width*(float64(i)+0.5), bottom+16, html.EscapeString(v))
+		}
+	} else {
+		step = niceStep(c.xMax, chartTicks)
+		for v := float64(0); v <= c.xMax+step/2; v += step {
+			fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, c.x(v), bottom+16, formatTick(v))
+		}
+	}
+
+	fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#000"/>`, left, bottom, right, bottom)
+	fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#000"/>`, left, top, left, bottom)
+	fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, (left+right)/2, chartHeight-8, html.EscapeString(c.xLabel))
+	fmt.Fprintf(b, `<text x="14" y="%.1f" text-anchor="middle" transform="rotate(-90 14 %.1f)">%s</text>`, (top+bottom)/2, (top+bottom)/2, html.EscapeString(c.yLabel))
+}
+
+// formatTick formats the value of a tick of an axis
+func formatTick(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%.3f", v), "0")
+}
+
+// emptyChart returns a chart noting that there is no data to draw
+func emptyChart(title string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<p class="empty">%s: no data in the results.</p>`, html.EscapeString(title)))
+}
+
+// lineChart draws the points, ordered by x, as a line
+func lineChart(c chart, points []point) template.HTML {
+	if len(points) == 0 {
+		return emptyChart(c.title)
+	}
+
+	var b strings.Builder
+	c.open(&b)
+
+	b.WriteString(`<polyline fill="none" stroke="#1f77b4" stroke-width="1.5" points="`)
+	for _, p := range points {
+		fmt.Fprintf(&b, "%.1f,%.1f ", c.x(p.x), c.y(p.y))
+	}
+	b.WriteString(`"/></svg>`)
+
+	return template.HTML(b.String())
+}
+
+// scatterChart draws the points as dots
+func scatterChart(c chart, points []point) template.HTML {
+	if len(points) == 0 {
+		return emptyChart(c.title)
+	}
+
+	var b strings.Builder
+	c.open(&b)
+
+	b.WriteString(`<g fill="#1f77b4" fill-opacity="0.4">`)
+	for _, p := range points {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="1.5"/>`, c.x(p.x), c.y(p.y))
+	}
+	b.WriteString(`</g></svg>`)
+
+	return template.HTML(b.String())
+}
+
+// barChart draws a bar for the value of each category
+func barChart(c chart, values []float64) template.HTML {
+	if len(values) == 0 {
+		return emptyChart(c.title)
+	}
+
+	var b strings.Builder
+	c.xMax = 1
+	c.open(&b)
+
+	width := (c.x(1) - c.x(0)) / float64(len(values))
+	for i, v := range values {
+		left := c.x(0) + width*(float64(i)+0.15)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#1f77b4"><title>%s</title></rect>`,
+			left, c.y(v), width*0.7, c.y(0)-c.y(v), formatFloat(v))
+	}
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+// throughputChart draws the throughput over time, from the aligned throughput
+// windows, the transaction records or else the throughput windows of the workers
+func throughputChart(results AggregatedResults) template.HTML {
+	c := chart{title: "Throughput over time", xLabel: "Time [s]", yLabel: "Throughput [tx/s]"}
+
+	var points []point
+	if results.ThroughputWindow > 0 {
+		for i, v := range results.TotalThroughputTimes {
+			points = append(points, point{float64(i) * results.ThroughputWindow / 1000, v})
+		}
+	} else if seconds, stats := intervalSeries(results); len(seconds) > 0 {
+		for _, second := range seconds {
+			points = append(points, point{float64(second), float64(stats[second].committed)})
+		}
+	} else {
+		c.xLabel = "Window"
+		for i, v := range results.TotalThroughputTimes {
+			points = append(points, point{float64(i), v})
+		}
+	}
+
+	for _, p := range points {
+		c.xMax = math.Max(c.xMax, p.x)
+		c.yMax = math.Max(c.yMax, p.y)
+	}
+	c.xMax = niceMax(c.xMax)
+	c.yMax = niceMax(c.yMax)
+
+	return lineChart(c, points)
+}
+
+// blocksChart draws the transactions of each block produced during the benchmark
+func blocksChart(results AggregatedResults) template.HTML {
+	c := chart{title: "Transactions per block", xLabel: "Block (from the start of the benchmark)", yLabel: "Transactions"}
+
+	var points []point
+	for _, b := range results.Blocks {
+		p := point{float64(b.Number - results.Blocks[0].Number), float64(b.Transactions)}
+		c.xMax = math.Max(c.xMax, p.x)
+		c.yMax = math.Max(c.yMax, p.y)
+		points = append(points, p)
+	}
+	c.xMax = niceMax(c.xMax)
+	c.yMax = niceMax(c.yMax)
+
+	return lineChart(c, points)
+}
+
+// latencyCDFChart draws the cumulative distribution of the latencies, from all
+// the latencies if known and else from the merged histogram
+func latencyCDFChart(results AggregatedResults) template.HTML {
+	c := chart{title: "Latency CDF", xLabel: "Latency [ms]", yLabel: "Fraction of transactions", yMax: 1}
+
+	var points []point
+	if len(results.AllTxLatencies) > 0 {
+		sortedLatencies := make([]float64, len(results.AllTxLatencies))
+		copy(sortedLatencies, results.AllTxLatencies)
+		sort.Float64s(sortedLatencies)
+
+		stride := int(math.Ceil(float64(len(sortedLatencies)) / maxCDFPoints))
+		for i := 0; i < len(sortedLatencies); i += stride {
+			points = append(points, point{sortedLatencies[i], float64(i+1) / float64(len(sortedLatencies))})
+		}
+		last := len(sortedLatencies) - 1
+		if points[len(points)-1].x != sortedLatencies[last] {
+			points = append(points, point{sortedLatencies[last], 1})
+		}
+	} else if results.Histogram != nil && results.Histogram.Count > 0 {
+		for i := 1; i <= maxCDFPoints; i++ {
+			fraction := float64(i) / maxCDFPoints
+			points = append(points, point{results.Histogram.Percentile(fraction * 100), fraction})
+		}
+	}
+
+	if len(points) > 0 {
+		c.xMax = niceMax(points[len(points)-1].x)
+	}
+
+	return lineChart(c, points)
+}
+
+// latencyScatterChart draws the latency of the committed transactions against
+// their commit time, sampled evenly if there are too many
+func latencyScatterChart(results AggregatedResults) template.HTML {
+	c := chart{title: "Latency over time", xLabel: "Commit time [s]", yLabel: "Latency [ms]"}
+
+	start := int64(-1)
+	var committed []TransactionRecord
+	for _, secondaryResult := range results.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Scheduled > 0 && (start < 0 || tx.Scheduled < start) {
+					start = tx.Scheduled
+				}
+				if tx.Committed > 0 && tx.Sent > 0 {
+					committed = append(committed, tx)
+				}
+			}
+		}
+	}
+
+	var points []point
+	stride := int(math.Ceil(float64(len(committed)) / maxScatterPoints))
+	for i := 0; i < len(committed); i += stride {
+		tx := committed[i]
+		p := point{float64(tx.Committed-start) / 1e9, float64(tx.Committed-tx.Sent) / 1e6}
+		points = append(points, p)
+		c.xMax = math.Max(c.xMax, p.x)
+		c.yMax = math.Max(c.yMax, p.y)
+	}
+	c.xMax = niceMax(c.xMax)
+	c.yMax = niceMax(c.yMax)
+
+	return scatterChart(c, points)
+}
+
+// secondaryCharts draws the throughput and average latency of each secondary
+func secondaryCharts(results AggregatedResults) []template.HTML {
+	var names []string
+	var throughputs, latencies []float64
+	for i, v := range results.SecondaryResults {
+		names = append(names, fmt.Sprintf("Secondary %d", i))
+		throughputs = append(throughputs, v.Throughput)
+		latencies = append(latencies, v.AverageLatency)
+	}
+
+	maxOf := func(values []float64) float64 {
+		max := float64(0)
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		return niceMax(max)
+	}
+
+	return []template.HTML{
+		barChart(chart{title: "Throughput per secondary", yLabel: "Throughput [tx/s]", yMax: maxOf(throughputs), categories: names}, throughputs),
+		barChart(chart{title: "Average latency per secondary", yLabel: "Latency [ms]", yMax: maxOf(latencies), categories: names}, latencies),
+	}
+}
+
+// reportConfig is a configuration file of the benchmark shown in the report
+type reportConfig struct {
+	Name    string // Name of the configuration
+	Content string // Content of the configuration file
+}
+
+// reportData is the data of the report template
+type reportData struct {
+	Title       string            // Title of the report
+	Generated   string            // Time the report was generated
+	Summary     [][2]string       // Summary statistics, name and value
+	Secondaries [][]string        // Summary row of each secondary
+	Functions   [][]string        // Summary row of each function
+	Types       [][]string        // Summary row of each type of function
+	Resources   [][]string        // Resources used by each secondary
+	Warnings    []string          // Warnings about the secondaries being the bottleneck
+	Errors      [][2]string       // Number of errors of each class
+	Assertions  []AssertionResult // Outcome of the assertions
+	Charts      []template.HTML   // Charts of the results
+	Configs     []reportConfig    // Configuration files of the benchmark
+}
+
+// reportTemplate is the template of the HTML report, a single file with no external assets
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 960px; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th { background: #f0f0f0; }
+td:first-child, th:first-child { text-align: left; }
+svg { display: block; margin: 1.5em 0; }
+pre { background: #f6f6f6; padding: 1em; overflow-x: auto; }
+.fail { color: #c00; }
+.empty { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{.Generated}}</p>
+
+<h2>Summary</h2>
+<table>
+{{range .Summary}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+<h2>Secondaries</h2>
+<table>
+<tr><th>Secondary</th><th>Throughput [tx/s]</th><th>Average latency [ms]</th><th>Median latency [ms]</th><th>p99 latency [ms]</th><th>Success</th><th>Fail</th><th>Completion</th></tr>
+{{range .Secondaries}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{if .Functions}}
+<h2>Functions</h2>
+<table>
+<tr><th>Function</th><th>Transactions</th><th>Success</th><th>Fail</th><th>Throughput [tx/s]</th><th>Average latency [ms]</th><th>p50 latency [ms]</th><th>p90 latency [ms]</th><th>p99 latency [ms]</th></tr>
+{{range .Functions}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}{{range .Types}}<tr>{{range .}}<th>{{.}}</th>{{end}}</tr>
+{{end}}</table>
+{{end}}{{if .Resources}}
+<h2>Secondary Resources</h2>
+{{range .Warnings}}<p class="fail">{{.}}</p>
+{{end}}<table>
+<tr><th>Secondary</th><th>CPUs</th><th>Average CPU [%]</th><th>Max CPU [%]</th><th>Max RSS [MB]</th><th>Max goroutines</th><th>GC pause [ms]</th><th>Received [MB]</th><th>Sent [MB]</th></tr>
+{{range .Resources}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}{{if .Errors}}
+<h2>Errors</h2>
+<table>
+<tr><th>Class</th><th>Transactions</th></tr>
+{{range .Errors}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Assertions}}
+<h2>Assertions</h2>
+<table>
+<tr><th>Assertion</th><th>Actual</th><th>Threshold</th><th>Result</th></tr>
+{{range .Assertions}}<tr><td>{{.Name}}</td><td>{{printf "%.3f" .Actual}}</td><td>{{printf "%.3f" .Threshold}}</td>{{if .Passed}}<td>PASS</td>{{else}}<td class="fail">FAIL</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+<h2>Charts</h2>
+{{range .Charts}}{{.}}
+{{end}}
+{{range .Configs}}<h2>{{.Name}}</h2>
+<pre>{{.Content}}</pre>
+{{end}}</body>
+</html>
+`))
+
+// breakdownRow returns the summary row of a function or type of function
+func breakdownRow(name string, v BreakdownResults) []string {
+	return []string{
+		name,
+		fmt.Sprintf("%d", v.Transactions),
+		fmt.Sprintf("%d", v.Success),
+		fmt.Sprintf("%d", v.Fails),
+		fmt.Sprintf("%.3f", v.Throughput),
+		fmt.Sprintf("%.3f", v.AverageLatency),
+		fmt.Sprintf("%.3f", v.Percentiles.P50),
+		fmt.Sprintf("%.3f", v.Percentiles.P90),
+		fmt.Sprintf("%.3f", v.Percentiles.P99),
+	}
+}
+
+// newReportData builds the data of the report of the results
+func newReportData(title string, results AggregatedResults, configs []reportConfig) reportData {
+	data := reportData{
+		Title:     title,
+		Generated: time.Now().Format(time.RFC3339),
+		Summary: [][2]string{
+			{"Throughput [tx/s]", fmt.Sprintf("%.3f (min %.3f, max %.3f)", results.AverageThroughput, results.MinThroughput, results.MaxThroughput)},
+			{"Average latency [ms]", fmt.Sprintf("%.3f (min %.3f, max %.3f)", results.AverageLatency, results.MinLatency, results.MaxLatency)},
+			{"Latency percentiles [ms]", fmt.Sprintf("p50 %.3f, p90 %.3f, p99 %.3f, p99.9 %.3f",
+				results.Percentiles.P50, results.Percentiles.P90, results.Percentiles.P99, results.Percentiles.P999)},
+			{"Successful transactions", fmt.Sprintf("%d", results.TotalSuccess)},
+			{"Failed transactions", fmt.Sprintf("%d", results.TotalFails)},
+			{"Secondaries", fmt.Sprintf("%d", len(results.SecondaryResults))},
+		},
+		Assertions: results.Assertions,
+		Configs:    configs,
+	}
+
+	if m := results.Metadata; m.RunID != "" {
+		data.Summary = append(data.Summary,
+			[2]string{"Run ID", m.RunID},
+			[2]string{"Run", fmt.Sprintf("%s to %s", m.Start.Format(time.RFC3339), m.End.Format(time.RFC3339))},
+			[2]string{"Diablo version", m.DiabloVersion},
+			[2]string{"Primary host", m.Host},
+			[2]string{"Benchmark configuration SHA-256", m.BenchConfigHash},
+			[2]string{"Chain configuration SHA-256", m.ChainConfigHash},
+		)
+		if m.Sweep != nil {
+			data.Summary = append(data.Summary, [2]string{"Sweep combination",
+				fmt.Sprintf("%d threads, %d secondaries, %d tps", m.Sweep.Threads, m.Sweep.Secondaries, m.Sweep.TPS)})
+		}
+	}
+
+	if results.Concurrency > 0 {
+		data.Summary = append(data.Summary,
+			[2]string{"Concurrency", fmt.Sprintf("%d (effective %.3f)", results.Concurrency, results.EffectiveConcurrency)})
+	}
+
+	for _, v := range []struct {
+		name  string
+		stage StageLatency
+	}{
+		{"Submission latency [ms]", results.Stages.Submission},
+		{"Inclusion latency [ms]", results.Stages.Inclusion},
+		{"Observation latency [ms]", results.Stages.Observation},
+		{"Finality latency [ms]", results.Stages.Finality},
+	} {
+		if v.stage.Transactions > 0 {
+			data.Summary = append(data.Summary, [2]string{v.name, fmt.Sprintf("avg %.3f, p50 %.3f, p99 %.3f, max %.3f (%d tx)",
+				v.stage.Average, v.stage.Percentiles.P50, v.stage.Percentiles.P99, v.stage.Max, v.stage.Transactions)})
+		} else if results.Stages.measured() {
+			// e.g. Fabric blocks carry no timestamp to measure the inclusion
+			data.Summary = append(data.Summary, [2]string{v.name, "not measured by the client interface"})
+		}
+	}
+
+	if b := results.BlockStats; b.Blocks > 0 {
+		data.Summary = append(data.Summary,
+			[2]string{"Blocks", fmt.Sprintf("%d (%d empty)", b.Blocks, b.EmptyBlocks)},
+			[2]string{"Block interval [ms]", fmt.Sprintf("avg %.3f, p50 %.3f, p99 %.3f, max %.3f",
+				b.Interval.Average, b.Interval.Percentiles.P50, b.Interval.Percentiles.P99, b.Interval.Max)},
+			[2]string{"Transactions per block", fmt.Sprintf("avg %.3f, p50 %.3f, p99 %.3f, max %.3f",
+				b.TransactionsPerBlock.Average, b.TransactionsPerBlock.Percentiles.P50, b.TransactionsPerBlock.Percentiles.P99, b.TransactionsPerBlock.Max)},
+			[2]string{"Diablo transactions in the blocks", fmt.Sprintf("%d of %d (%.1f%%)",
+				b.DiabloTransactions, b.DiabloTransactions+b.ForeignTransactions, 100*b.DiabloFraction)},
+		)
+		if b.Fullness.Max > 0 {
+			data.Summary = append(data.Summary, [2]string{"Block fullness [%]", fmt.Sprintf("avg %.3f, p50 %.3f, p99 %.3f, max %.3f",
+				b.Fullness.Average, b.Fullness.Percentiles.P50, b.Fullness.Percentiles.P99, b.Fullness.Max)})
+		}
+	}
+
+	for i, v := range results.SecondaryResults {
+		name := fmt.Sprintf("%d", i)
+		if i < len(results.Metadata.Secondaries) {
+			info := results.Metadata.Secondaries[i]
+			name = fmt.Sprintf("%d (%s, %d threads)", i, info.Address, info.Threads)
+		}
+
+		data.Secondaries = append(data.Secondaries, []string{
+			name,
+			fmt.Sprintf("%.3f", v.Throughput),
+			fmt.Sprintf("%.3f", v.AverageLatency),
+			fmt.Sprintf("%.3f", v.MedianLatency),
+			fmt.Sprintf("%.3f", v.Percentiles.P99),
+			fmt.Sprintf("%d", v.Success),
+			fmt.Sprintf("%d", v.Fail),
+			string(v.CompletionReason),
+		})
+	}
+
+	for _, v := range results.FunctionBreakdown {
+		data.Functions = append(data.Functions, breakdownRow(v.Name, v))
+	}
+	for _, v := range results.TypeBreakdown {
+		data.Types = append(data.Types, breakdownRow("All "+v.Name+"s", v))
+	}
+
+	for i, v := range results.Resources {
+		if len(v.Samples) == 0 {
+			continue
+		}
+		data.Resources = append(data.Resources, []string{
+			fmt.Sprintf("%d", i),
+			fmt.Sprintf("%d", v.NumCPU),
+			fmt.Sprintf("%.1f", v.AverageCPU),
+			fmt.Sprintf("%.1f", v.MaxCPU),
+			fmt.Sprintf("%d", v.MaxRSS>>20),
+			fmt.Sprintf("%d", v.MaxGoroutines),
+			fmt.Sprintf("%.3f", v.GCPause),
+			fmt.Sprintf("%d", v.NetRx>>20),
+			fmt.Sprintf("%d", v.NetTx>>20),
+		})
+	}
+	data.Warnings = results.ResourceWarnings
+
+	for _, class := range sortedErrorClasses(results.ErrorBreakdown) {
+		data.Errors = append(data.Errors, [2]string{string(class), fmt.Sprintf("%d", results.ErrorBreakdown[class])})
+	}
+
+	data.Charts = append(data.Charts, throughputChart(results), latencyCDFChart(results), latencyScatterChart(results))
+	data.Charts = append(data.Charts, secondaryCharts(results)...)
+	if len(results.Blocks) > 0 {
+		data.Charts = append(data.Charts, blocksChart(results))
+	}
+
+	return data
+}
+
+// readReportConfigs reads the configuration files that exist among the given
+// ones, keyed by the name shown in the report
+func readReportConfigs(names []string, paths []string) []reportConfig {
+	var configs []reportConfig
+	for i, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		configs = append(configs, reportConfig{Name: names[i], Content: string(content)})
+	}
+
+	return configs
+}
+
+// writeReport writes the HTML report of the results, including the
+// configurations of the benchmark and the chain from the given files
+func writeReport(path string, title string, results AggregatedResults, benchConfig string, chainConfig string) error {
+	configs := readReportConfigs(
+		[]string{"Benchmark configuration", "Chain configuration"},
+		[]string{benchConfig, chainConfig},
+	)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return reportTemplate.Execute(f, newReportData(title, results, configs))
+}
+
+// reportPrefix returns the prefix (directory and timestamp) of the files
+// written along with the results file
+func reportPrefix(resultsPath string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(resultsPath, ".json"), "_results")
+}
+
+// GenerateReport writes the HTML report of a results file next to it, with the
+// configurations copied along with the results, and returns the report path
+func GenerateReport(resultsPath string) (string, error) {
+	results, err := LoadResults(resultsPath)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := reportPrefix(resultsPath)
+	path := prefix + "_report.html"
+	err = writeReport(path, "Diablo results "+filepath.Base(prefix), results, prefix+"_workload.yaml", prefix+"_chain.yaml")
+
+	return path, err
+}
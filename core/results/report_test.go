@@ -0,0 +1,109 @@
+package results
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateReport(t *testing.T) {
+	s := int64(time.Second)
+	ms := int64(time.Millisecond)
+
+	res := CalculateAggregatedResults([][]Results{{{
+		TxLatencies:       []float64{100, 200},
+		ThroughputSeconds: []float64{2},
+		Success:           2,
+		Fail:              1,
+		Errors:            map[ErrorClass]uint{ErrorRevert: 1},
+		Transactions: []TransactionRecord{
+			{Interval: 0, Scheduled: 10 * s, Sent: 10 * s, Acknowledged: 10*s + 50*ms, Committed: 10*s + 100*ms},
+			{Interval: 0, Scheduled: 10*s + 500*ms, Sent: 11 * s, Committed: 11*s + 200*ms},
+			{Interval: 1, Scheduled: 11 * s, Sent: 11 * s, Error: ErrorRevert},
+		},
+	}}})
+	CalculateErrorBreakdown(&res)
+	CalculateLatencyStages(&res)
+
+	dir := t.TempDir()
+	prefix := filepath.Join(dir, "2020-01-01T00:00:00Z")
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(prefix+"_results.json", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(prefix+"_workload.yaml", []byte("name: <sample>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := GenerateReport(prefix + "_results.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != prefix+"_report.html" {
+		t.Errorf("unexpected report path %s", path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(content)
+
+	t.Run("self-contained charts", func(t *testing.T) {
+		if n := strings.Count(report, "<svg"); n != 5 {
+			t.Errorf("expected 5 charts, got %d", n)
+		}
+		if strings.Contains(report, "src=") || strings.Contains(report, "href=") {
+			t.Error("the report should not reference external assets")
+		}
+	})
+
+	t.Run("configurations and errors", func(t *testing.T) {
+		if !strings.Contains(report, "name: &lt;sample&gt;") {
+			t.Error("expected the escaped benchmark configuration in the report")
+		}
+		if strings.Contains(report, "Chain configuration") {
+			t.Error("the missing chain configuration should be skipped")
+		}
+		if !strings.Contains(report, string(ErrorRevert)) {
+			t.Error("expected the error breakdown in the report")
+		}
+	})
+
+	t.Run("unmeasured stages", func(t *testing.T) {
+		if !strings.Contains(report, "Submission latency [ms]") {
+			t.Error("expected the measured submission stage in the report")
+		}
+		if !strings.Contains(report, "not measured by the client interface") {
+			t.Error("expected the unmeasured stages to be reported as such")
+		}
+	})
+
+	t.Run("missing results", func(t *testing.T) {
+		if _, err := GenerateReport(filepath.Join(dir, "missing_results.json")); err == nil {
+			t.Error("expected an error for a missing results file")
+		}
+	})
+}
+
+func TestNiceStep(t *testing.T) {
+	for _, v := range []struct {
+		max  float64
+		step float64
+	}{
+		{max: 0, step: 1},
+		{max: 7, step: 2},
+		{max: 100, step: 20},
+		{max: 0.9, step: 0.2},
+	} {
+		if step := niceStep(v.max, chartTicks); step != v.step {
+			t.Errorf("niceStep(%v): expected %v, got %v", v.max, v.step, step)
+		}
+	}
+}
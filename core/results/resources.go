@@ -0,0 +1,118 @@
+package results
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Thresholds beyond which a secondary is considered the bottleneck of the benchmark
+const (
+	BottleneckCPU = 85 // Average CPU use [% of the CPUs of the secondary]
+	BottleneckGC  = 5  // Time paused by the garbage collector [% of the benchmark]
+)
+
+// ResourceSample is a sample of the resources used by the secondary process
+type ResourceSample struct {
+	Time       int64   `json:"Time"`       // Time of the sample (unix nanoseconds)
+	CPU        float64 `json:"CPU"`        // CPU used since the previous sample [% of one CPU]
+	RSS        uint64  `json:"RSS"`        // Resident memory [bytes]
+	HeapAlloc  uint64  `json:"HeapAlloc"`  // Memory allocated on the Go heap [bytes]
+	Goroutines int     `json:"Goroutines"` // Number of goroutines
+	GCPause    float64 `json:"GCPause"`    // Time paused by the garbage collector since the previous sample [ms]
+	NetRx      uint64  `json:"NetRx"`      // Bytes received since the previous sample
+	NetTx      uint64  `json:"NetTx"`      // Bytes sent since the previous sample
+}
+
+// ResourceUsage is the resources used by a secondary during the benchmark
+type ResourceUsage struct {
+	NumCPU        int              `json:"NumCPU"`        // Number of CPUs of the secondary
+	AverageCPU    float64          `json:"AverageCPU"`    // Average CPU use [% of one CPU]
+	MaxCPU        float64          `json:"MaxCPU"`        // Maximum CPU use of a sample [% of one CPU]
+	MaxRSS        uint64           `json:"MaxRSS"`        // Maximum resident memory [bytes]
+	MaxHeapAlloc  uint64           `json:"MaxHeapAlloc"`  // Maximum memory allocated on the Go heap [bytes]
+	MaxGoroutines int              `json:"MaxGoroutines"` // Maximum number of goroutines
+	GCPause       float64          `json:"GCPause"`       // Time paused by the garbage collector [ms]
+	NetRx         uint64           `json:"NetRx"`         // Bytes received
+	NetTx         uint64           `json:"NetTx"`         // Bytes sent
+	Duration      float64          `json:"Duration"`      // Time covered by the samples [ms]
+	Samples       []ResourceSample `json:"Samples"`       // Samples of the resources over the benchmark
+}
+
+// NewResourceUsage summarises the samples of the resources of a secondary with
+// the given number of CPUs
+func NewResourceUsage(numCPU int, samples []ResourceSample) ResourceUsage {
+	usage := ResourceUsage{NumCPU: numCPU, Samples: samples}
+	if len(samples) == 0 {
+		return usage
+	}
+
+	for _, v := range samples {
+		usage.AverageCPU += v.CPU
+		if v.CPU > usage.MaxCPU {
+			usage.MaxCPU = v.CPU
+		}
+		if v.RSS > usage.MaxRSS {
+			usage.MaxRSS = v.RSS
+		}
+		if v.HeapAlloc > usage.MaxHeapAlloc {
+			usage.MaxHeapAlloc = v.HeapAlloc
+		}
+		if v.Goroutines > usage.MaxGoroutines {
+			usage.MaxGoroutines = v.Goroutines
+		}
+		usage.GCPause += v.GCPause
+		usage.NetRx += v.NetRx
+		usage.NetTx += v.NetTx
+	}
+	usage.AverageCPU = usage.AverageCPU / float64(len(samples))
+
+	if len(samples) > 1 {
+		usage.Duration = float64(samples[len(samples)-1].Time-samples[0].Time) / 1e6
+	}
+
+	return usage
+}
+
+// bottleneck returns why the secondary appears to be the bottleneck of the
+// benchmark, empty if it does not
+func (u ResourceUsage) bottleneck() string {
+	if u.NumCPU > 0 {
+		if cpu := u.AverageCPU / float64(u.NumCPU); cpu >= BottleneckCPU {
+			return fmt.Sprintf("used %.0f%% of its %d CPUs on average", cpu, u.NumCPU)
+		}
+	}
+
+	if u.Duration > 0 {
+		if gc := 100 * u.GCPause / u.Duration; gc >= BottleneckGC {
+			return fmt.Sprintf("was paused by the garbage collector %.1f%% of the time", gc)
+		}
+	}
+
+	return ""
+}
+
+// CalculateResourceUsage collects the resources used by each secondary, returned
+// with the results of its first worker, and warns about the secondaries that
+// appear to be the bottleneck of the benchmark rather than the chain.
+func CalculateResourceUsage(res *AggregatedResults) {
+	res.Resources = nil
+	res.ResourceWarnings = nil
+
+	for secondaryID, secondaryResult := range res.RawResults {
+		var usage ResourceUsage
+		for _, workerResult := range secondaryResult {
+			if workerResult.Resources != nil {
+				usage = *workerResult.Resources
+				break
+			}
+		}
+		res.Resources = append(res.Resources, usage)
+
+		if reason := usage.bottleneck(); reason != "" {
+			warning := fmt.Sprintf("secondary %d %s, it may be the bottleneck of the benchmark", secondaryID, reason)
+			zap.L().Warn(warning)
+			res.ResourceWarnings = append(res.ResourceWarnings, warning)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package results
+
+import "testing"
+
+func TestCalculateResourceUsage(t *testing.T) {
+	busy := NewResourceUsage(2, []ResourceSample{
+		{Time: 0, CPU: 190, Goroutines: 10},
+		{Time: 1e9, CPU: 180, Goroutines: 30, RSS: 1 << 20},
+	})
+	idle := NewResourceUsage(4, []ResourceSample{
+		{Time: 0, CPU: 20, GCPause: 1},
+		{Time: 1e9, CPU: 40, GCPause: 2},
+	})
+
+	if busy.AverageCPU != 185 || busy.MaxCPU != 190 || busy.MaxGoroutines != 30 || busy.Duration != 1000 {
+		t.Errorf("unexpected summary of the samples %+v", busy)
+	}
+
+	res := CalculateAggregatedResults([][]Results{
+		{{Resources: &busy}, {}},
+		{{Resources: &idle}},
+		{{}},
+	})
+	CalculateResourceUsage(&res)
+
+	t.Run("resources per secondary", func(t *testing.T) {
+		if len(res.Resources) != 3 || res.Resources[1].NumCPU != 4 || len(res.Resources[2].Samples) != 0 {
+			t.Errorf("unexpected resources %+v", res.Resources)
+		}
+	})
+
+	t.Run("bottleneck", func(t *testing.T) {
+		if len(res.ResourceWarnings) != 1 {
+			t.Fatalf("expected a warning for the busy secondary, got %v", res.ResourceWarnings)
+		}
+	})
+}
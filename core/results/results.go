@@ -8,6 +8,7 @@ package results
 
 import (
 	"fmt"
+	"math"
 	"sort"
 
 	"go.uber.org/zap"
@@ -22,11 +23,27 @@ type Results struct {
 	ThroughputSeconds []float64 `json:"ThroughputSeconds"` // Number of transactions "committed" over second periods to measure dynamic throughput
 	Success           uint      // Number of successful transactions
 	Fail              uint      // Number of failed transactions
+
+	Transactions []TransactionRecord `json:"Transactions,omitempty"` // Record of each transaction sent by the worker
+	Errors       map[ErrorClass]uint `json:"Errors,omitempty"`       // Number of errors per class
+
+	CompletionReason CompletionReason `json:"CompletionReason,omitempty"` // Criterion that ended the benchmark on the secondary
+	Node             string           `json:"Node,omitempty"`             // Node the worker sent its transactions to
+	Host             string           `json:"Host,omitempty"`             // Host name of the secondary running the worker
+
+	Blocks    []BlockRecord  `json:"Blocks,omitempty"`    // Blocks produced during the benchmark, walked by the first worker of the first secondary
+	Resources *ResourceUsage `json:"Resources,omitempty"` // Resources used by the secondary, returned by its first worker
+
+	Percentiles LatencyPercentiles `json:"Percentiles"`         // Percentiles of the latencies of the transactions
+	Histogram   *LatencyHistogram  `json:"Histogram,omitempty"` // Histogram of the latencies, replaces TxLatencies if they are not returned
 }
 
 // AggregatedResults returns all the information from all secondaries, and
 // stores the calculated information (e.g. max, min, ...)
 type AggregatedResults struct {
+	// Metadata
+	Metadata Metadata `json:"Metadata"` // Run that produced the results
+
 	// Results
 	RawResults       [][]Results `json:"RawResults"`       // Results of [secondary][thread]
 	SecondaryResults []Results   `json:"SecondaryResults"` // Aggregation of results per secondary
@@ -35,8 +52,19 @@ type AggregatedResults struct {
 	MinLatency     float64   `json:"MinLatency"`     // Minimum latency across all workers and secondaries
 	AverageLatency float64   `json:"AverageLatency"` // Average latency across all workers and secondaries
 	MaxLatency     float64   `json:"MaxLatency"`     // Maximum Latency across all workers and secondaries
-	MedianLatency  float64   `json:"MedianLatency"`  // Median Latency across all workers and secondaries
-	AllTxLatencies []float64 `json:"AllTxLatencies"` // All Transaction Latencies
+	MedianLatency  float64   `json:"MedianLatency"`  // Median Latency across all transactions
+	AllTxLatencies []float64 `json:"AllTxLatencies"` // All Transaction Latencies, empty if the secondaries returned histograms
+
+	Percentiles LatencyPercentiles `json:"Percentiles"`         // Percentiles of the latencies across all transactions
+	Histogram   *LatencyHistogram  `json:"Histogram,omitempty"` // Histogram of the latencies across all transactions
+
+	// Latency from the scheduled and actual send time of each transaction
+	ScheduledLatency LatencySummary `json:"ScheduledLatency"`          // Latency measured from the scheduled send time
+	SentLatency      LatencySummary `json:"SentLatency"`               // Latency measured from the actual send time
+	LaggedIntervals  []IntervalLag  `json:"LaggedIntervals,omitempty"` // Intervals where sending lagged the schedule
+
+	// Latency of each stage of the transactions
+	Stages LatencyStages `json:"Stages"` // Latency from the send to the acknowledgement, inclusion, observation and finality
 
 	// Throughput
 	TotalThroughputTimes         []float64   `json:"TotalThroughputOverTime"`              // Total throughput over time per window
@@ -45,14 +73,44 @@ type AggregatedResults struct {
 	MaxThroughput                float64     `json:"MaximumThroughput"`                    // Maximum Throughput reached over time
 	MinThroughput                float64     `json:"MinimumThroughput"`                    // Miniumum Throughput reached overall
 	AverageThroughput            float64     `json:"AverageThroughput"`                    // Average throughput reached overall
+	ThroughputWindow             float64     `json:"ThroughputWindow,omitempty"`           // Size of the windows of the throughput over time [ms], 0 if measured by the workers
+	ThroughputStart              int64       `json:"ThroughputStart,omitempty"`            // Start of the first throughput window (unix nanoseconds)
 
 	// Success and Fail
 	TotalSuccess uint `json:"TotalSuccess"` // Total number of successes
 	TotalFails   uint `json:"TotalFails"`   // Total number of fails
+
+	// Errors
+	ErrorBreakdown map[ErrorClass]uint `json:"ErrorBreakdown,omitempty"` // Number of errors per class
+	IntervalErrors []IntervalErrors    `json:"IntervalErrors,omitempty"` // Number of errors per class in each interval
+
+	// Functions
+	FunctionBreakdown []BreakdownResults `json:"FunctionBreakdown,omitempty"` // Results per function called
+	TypeBreakdown     []BreakdownResults `json:"TypeBreakdown,omitempty"`     // Results per type of function (read or write)
+
+	// Blocks
+	Blocks     []BlockRecord `json:"Blocks,omitempty"` // Blocks produced during the benchmark
+	BlockStats BlockStats    `json:"BlockStats"`       // Analytics of the blocks produced during the benchmark
+
+	// Resources
+	Resources        []ResourceUsage `json:"Resources,omitempty"`        // Resources used by each secondary
+	ResourceWarnings []string        `json:"ResourceWarnings,omitempty"` // Secondaries that appear to be the bottleneck of the benchmark
+
+	// Closed loop
+	Concurrency          int     `json:"Concurrency,omitempty"`          // Maximum outstanding transactions across all workers (closed loop)
+	EffectiveConcurrency float64 `json:"EffectiveConcurrency,omitempty"` // Average outstanding transactions, throughput x latency (closed loop)
+
+	// Assertions
+	Assertions       []AssertionResult `json:"Assertions,omitempty"` // Outcome of each assertion defined in the benchmark
+	AssertionsPassed bool              `json:"AssertionsPassed"`     // Whether all assertions passed
 }
 
-// Return the median of a list
+// Return the median of a list, 0 if empty
 func getMedian(arr []float64) float64 {
+	if len(arr) == 0 {
+		return 0
+	}
+
 	arrSorted := arr
 	sort.Float64s(arrSorted)
 
@@ -63,6 +121,23 @@ func getMedian(arr []float64) float64 {
 	return arrSorted[midNumber]
 }
 
+// Return the given percentile (0 - 100) of a sorted list using the nearest-rank method
+func getPercentile(arrSorted []float64, percentile float64) float64 {
+	if len(arrSorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(arrSorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(arrSorted) {
+		rank = len(arrSorted)
+	}
+
+	return arrSorted[rank-1]
+}
+
 // CalculateAggregatedResults calculates the aggregated results given the set of results from the secondaries
 func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults {
 
@@ -81,12 +156,10 @@ func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults
 	// Total throughput per secondary per second (throughput over time)
 	var throughputOverTimeSecondary [][]float64
 
-	// Min/Max/Average Latency
-	maxTotalLatency := float64(0)
-	averageTotalLatency := float64(0)
-	minTotalLatency := secondaryResults[0][0].AverageLatency
-
-	var latencyPerSecondary []float64
+	// Latencies across all secondaries, the raw latencies are only kept if
+	// every worker returned them
+	allLatencies := NewLatencyHistogram(nil)
+	allRaw := true
 	var allTxLatencies []float64
 
 	// Throughput total
@@ -99,30 +172,22 @@ func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults
 	// Iterate through the results
 	for secondaryID, secondaryResult := range secondaryResults {
 		txLatencies := make([]float64, 0)
-		averageLatencyPerSecondary := float64(0)
+		secondaryLatencies := NewLatencyHistogram(nil)
+		secondaryRaw := true
 		secondaryThroughputs := make([]float64, 0)
-		latencyEntries := float64(0)
 		avgThroughputPerSecondary := float64(0)
 		// For each worker
 		numSuccess := uint(0)
 		numFails := uint(0)
 		for workerID, workerResult := range secondaryResult {
-			// 1. get the latency average per secondary
-			latencyEntries += float64(len(workerResult.TxLatencies))
+			// 1. merge the latencies of the worker into the secondary
 			numSuccess += workerResult.Success
 			numFails += workerResult.Fail
-			for _, v := range workerResult.TxLatencies {
-				averageLatencyPerSecondary += v
-				if minTotalLatency > v && v > 0 {
-					minTotalLatency = v
-				}
-
-				if v > maxTotalLatency {
-					maxTotalLatency = v
-				}
-
-				txLatencies = append(txLatencies, v)
-				allTxLatencies = append(allTxLatencies, v)
+			secondaryLatencies.Merge(workerResult.latencyHistogram())
+			if workerResult.hasRawLatencies() {
+				txLatencies = append(txLatencies, workerResult.TxLatencies...)
+			} else {
+				secondaryRaw = false
 			}
 
 			// 2. Obtain throughputs
@@ -145,34 +210,35 @@ func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults
 			)
 		}
 
-		// fix the average latencies
-		avgLatency := float64(0)
-		for idx := 0; idx < len(txLatencies); idx++ {
-			avgLatency += txLatencies[idx]
-		}
-		if len(txLatencies) > 0 {
-			avgLatency = avgLatency / float64(len(txLatencies))
-		}
-		latencyPerSecondary = append(latencyPerSecondary, avgLatency)
-
-		// Get the median latency by sorting -> getting middle number
-		sortedLatencies := txLatencies
-		sort.Float64s(sortedLatencies)
-		medianLatency := float64(0)
-		if len(txLatencies) > 0 {
-			midNumber := len(txLatencies) / 2
-			if len(txLatencies)%2 == 0 {
-				medianLatency = (txLatencies[midNumber-1] + txLatencies[midNumber]) / 2
-			} else {
-				medianLatency = txLatencies[midNumber]
-			}
+		avgLatency := secondaryLatencies.Mean()
+
+		// The median and percentiles are exact if the latency of every
+		// transaction is known, else they come from the histogram
+		var medianLatency float64
+		var percentiles LatencyPercentiles
+		if secondaryRaw {
+			sort.Float64s(txLatencies)
+			medianLatency = getMedian(txLatencies)
+			percentiles = percentilesOf(txLatencies)
+			allTxLatencies = append(allTxLatencies, txLatencies...)
+		} else {
+			medianLatency = secondaryLatencies.Percentile(50)
+			percentiles = secondaryLatencies.Percentiles()
+			txLatencies = nil
+			allRaw = false
 		}
+		allLatencies.Merge(secondaryLatencies)
 
 		// Total and averages
-		averageTotalLatency += avgLatency
 		throughputOverTimeSecondary = append(throughputOverTimeSecondary, secondaryThroughputs)
 		throughputPerSecondary = append(throughputPerSecondary, avgThroughputPerSecondary/float64(len(secondaryResult)))
 
+		// All workers of a secondary end on the same criterion
+		var completionReason CompletionReason
+		if len(secondaryResult) > 0 {
+			completionReason = secondaryResult[0].CompletionReason
+		}
+
 		ResultsPerSecondary = append(ResultsPerSecondary, Results{
 			TxLatencies:       txLatencies,
 			ThroughputSeconds: secondaryThroughputs,
@@ -181,6 +247,9 @@ func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults
 			MedianLatency:     medianLatency,
 			Success:           numSuccess,
 			Fail:              numFails,
+			CompletionReason:  completionReason,
+			Percentiles:       percentiles,
+			Histogram:         secondaryLatencies,
 		})
 
 		// Update the number of total success and failures
@@ -188,25 +257,39 @@ func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults
 		totalFails += numFails
 	}
 
-	// Fix up the average and median latency
-	averageTotalLatency = averageTotalLatency / float64(len(secondaryResults))
-	medianLatencyTotal := getMedian(latencyPerSecondary)
+	// The average and median latencies are over all transactions
+	var medianLatencyTotal float64
+	var percentilesTotal LatencyPercentiles
+	if allRaw {
+		sort.Float64s(allTxLatencies)
+		medianLatencyTotal = getMedian(allTxLatencies)
+		percentilesTotal = percentilesOf(allTxLatencies)
+	} else {
+		allTxLatencies = nil
+		medianLatencyTotal = allLatencies.Percentile(50)
+		percentilesTotal = allLatencies.Percentiles()
+	}
 
-	// Fix up the overall throughput and average throughput
-	minTotalThroughput := totalThroughputOverTime[0]
-	for _, v := range totalThroughputOverTime {
+	// Fix up the overall throughput and average throughput. This series of
+	// the tickers of the workers is only kept when the secondaries return no
+	// transaction records (histogram mode), CalculateThroughputWindows
+	// replaces it otherwise. It is empty if no worker ticked.
+	minTotalThroughput := float64(0)
+	for i, v := range totalThroughputOverTime {
 		if v > maxTotalThroughput {
 			maxTotalThroughput = v
 		}
 
 		// NOTE - need to check out the minimum throughput, because of the 0 throughput if waiting for timeouts
-		if v < minTotalThroughput {
+		if i == 0 || v < minTotalThroughput {
 			minTotalThroughput = v
 		}
 		averageTotalThroughput += v
 	}
 
-	averageTotalThroughput = averageTotalThroughput / float64(len(totalThroughputOverTime))
+	if len(totalThroughputOverTime) > 0 {
+		averageTotalThroughput = averageTotalThroughput / float64(len(totalThroughputOverTime))
+	}
 
 	// DEBUG PURPOSES ONLY
 	var avgThroughputAvg float64
@@ -224,10 +307,12 @@ func CalculateAggregatedResults(secondaryResults [][]Results) AggregatedResults
 	return AggregatedResults{
 		RawResults:                   secondaryResults,
 		SecondaryResults:             ResultsPerSecondary,
-		MinLatency:                   minTotalLatency,
-		AverageLatency:               averageTotalLatency,
+		MinLatency:                   allLatencies.Min,
+		AverageLatency:               allLatencies.Mean(),
 		MedianLatency:                medianLatencyTotal,
-		MaxLatency:                   maxTotalLatency,
+		MaxLatency:                   allLatencies.Max,
+		Percentiles:                  percentilesTotal,
+		Histogram:                    allLatencies,
 		TotalThroughputTimes:         totalThroughputOverTime,
 		AverageThroughputSecondary:   throughputPerSecondary,
 		TotalThroughputSecondaryTime: throughputOverTimeSecondary,
@@ -0,0 +1,132 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SecondaryResults are the results of the workers of a secondary, written
+// locally by the secondary at the end of the run so that they can still be
+// merged if the primary fails to collect them
+type SecondaryResults struct {
+	SchemaVersion int       `json:"SchemaVersion"` // Version of the schema of the results
+	SecondaryID   int       `json:"SecondaryID"`   // ID of the secondary
+	Host          string    `json:"Host"`          // Host name of the secondary
+	Start         time.Time `json:"Start"`         // Start of the benchmark on the secondary
+	End           time.Time `json:"End"`           // End of the benchmark on the secondary
+	Results       []Results `json:"Results"`       // Results of the workers, with their transaction records
+}
+
+// WriteSecondaryResults writes the results of a secondary to a JSON file in the
+// given directory and returns the path of the file
+func WriteSecondaryResults(resultDir string, sr SecondaryResults) (string, error) {
+	if !checkFileExists(resultDir) {
+		zap.L().Warn(fmt.Sprintf("Directory %s does not exist, creating it", resultDir))
+		err := os.Mkdir(resultDir, 0755)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sr.SchemaVersion = SchemaVersion
+	f, err := json.Marshal(sr)
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/%s_secondary_%d_results.json", resultDir, sr.Start.Format("20060102T150405"), sr.SecondaryID)
+	if err = ioutil.WriteFile(path, f, 0644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// firstSecondarySchemaVersion is the schema version the secondaries first
+// wrote their results with
+const firstSecondarySchemaVersion = 3
+
+// secondaryResultsUpgrades upgrade the results of a secondary from the schema
+// version (key) to the next one, versions that did not change them have none
+var secondaryResultsUpgrades = map[int]func(sr *SecondaryResults){}
+
+// UpgradeSecondaryResults upgrades the results of a secondary of an older
+// schema version to the current one
+func UpgradeSecondaryResults(sr *SecondaryResults) error {
+	version := sr.SchemaVersion
+	if version < firstSecondarySchemaVersion {
+		return fmt.Errorf("secondary results of unknown schema version %d", version)
+	}
+
+	if version > SchemaVersion {
+		return fmt.Errorf("secondary results of schema version %d are newer than the supported version %d", version, SchemaVersion)
+	}
+
+	for ; version < SchemaVersion; version++ {
+		if upgrade, ok := secondaryResultsUpgrades[version]; ok {
+			upgrade(sr)
+		}
+	}
+	sr.SchemaVersion = SchemaVersion
+
+	return nil
+}
+
+// LoadSecondaryResults reads the results written by a secondary, upgrading
+// results written by older versions of Diablo to the current schema
+func LoadSecondaryResults(path string) (SecondaryResults, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return SecondaryResults{}, err
+	}
+
+	var sr SecondaryResults
+	if err = json.Unmarshal(content, &sr); err != nil {
+		return SecondaryResults{}, fmt.Errorf("failed to parse secondary results %s: %s", path, err.Error())
+	}
+
+	if err = UpgradeSecondaryResults(&sr); err != nil {
+		return SecondaryResults{}, fmt.Errorf("failed to load secondary results %s: %s", path, err.Error())
+	}
+
+	return sr, nil
+}
+
+// MergeSecondaryResults sorts the results of the secondaries in place by their
+// ID, the order the primary collects them in, and returns them with the earliest
+// start and the latest end of the secondaries. The results of a secondary must
+// only be given once.
+func MergeSecondaryResults(secondaries []SecondaryResults) ([][]Results, time.Time, time.Time, error) {
+	if len(secondaries) == 0 {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("no secondary results to merge")
+	}
+
+	sort.SliceStable(secondaries, func(i, j int) bool {
+		return secondaries[i].SecondaryID < secondaries[j].SecondaryID
+	})
+
+	var rawResults [][]Results
+	start, end := secondaries[0].Start, secondaries[0].End
+	for i, sr := range secondaries {
+		if i > 0 && secondaries[i-1].SecondaryID == sr.SecondaryID {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("results of secondary %d given more than once", sr.SecondaryID)
+		}
+
+		if sr.Start.Before(start) {
+			start = sr.Start
+		}
+		if sr.End.After(end) {
+			end = sr.End
+		}
+
+		rawResults = append(rawResults, sr.Results)
+	}
+
+	return rawResults, start, end, nil
+}
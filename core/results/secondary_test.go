@@ -0,0 +1,80 @@
+package results
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSecondaryResults(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := int64(time.Second)
+
+	sr := SecondaryResults{
+		SecondaryID: 1,
+		Host:        "b",
+		Start:       start,
+		End:         start.Add(time.Minute),
+		Results: []Results{{
+			Host:         "b",
+			Transactions: []TransactionRecord{{Sent: s, Committed: 2 * s}},
+		}},
+	}
+
+	t.Run("write and load", func(t *testing.T) {
+		path, err := WriteSecondaryResults(filepath.Join(dir, "results"), sr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadSecondaryResults(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(path) != "20210601T120000_secondary_1_results.json" {
+			t.Errorf("unexpected file name %s", filepath.Base(path))
+		}
+		if loaded.SecondaryID != 1 || loaded.SchemaVersion != SchemaVersion || !loaded.End.Equal(sr.End) {
+			t.Errorf("unexpected secondary results %+v", loaded)
+		}
+		if len(loaded.Results) != 1 || len(loaded.Results[0].Transactions) != 1 || loaded.Results[0].Transactions[0].Committed != 2*s {
+			t.Errorf("expected the transaction records to be kept, got %+v", loaded.Results)
+		}
+	})
+
+	t.Run("schema versions", func(t *testing.T) {
+		first := SecondaryResults{SchemaVersion: firstSecondarySchemaVersion}
+		if err := UpgradeSecondaryResults(&first); err != nil || first.SchemaVersion != SchemaVersion {
+			t.Errorf("expected the results to be upgraded to version %d, got %d (%v)", SchemaVersion, first.SchemaVersion, err)
+		}
+
+		path := filepath.Join(dir, "newer.json")
+		if err := ioutil.WriteFile(path, []byte(`{"SchemaVersion": 99}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSecondaryResults(path); err == nil {
+			t.Error("expected an error for secondary results of a newer schema version")
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		first := SecondaryResults{SecondaryID: 0, Start: start.Add(-time.Second), End: start.Add(30 * time.Second), Results: []Results{{Host: "a"}}}
+
+		rawResults, mergedStart, mergedEnd, err := MergeSecondaryResults([]SecondaryResults{sr, first})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rawResults) != 2 || rawResults[0][0].Host != "a" || rawResults[1][0].Host != "b" {
+			t.Errorf("expected the results ordered by secondary, got %+v", rawResults)
+		}
+		if !mergedStart.Equal(first.Start) || !mergedEnd.Equal(sr.End) {
+			t.Errorf("unexpected start %v and end %v", mergedStart, mergedEnd)
+		}
+
+		if _, _, _, err = MergeSecondaryResults([]SecondaryResults{sr, sr}); err == nil {
+			t.Error("expected an error for the results of a secondary given twice")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package results
+
+import (
+	"sort"
+	"time"
+)
+
+// StageLatency is the distribution of the latency of a stage of the transactions
+type StageLatency struct {
+	Transactions uint               `json:"Transactions"` // Number of transactions the stage was measured for
+	Average      float64            `json:"Average"`      // Average latency of the stage [ms]
+	Percentiles  LatencyPercentiles `json:"Percentiles"`  // Percentiles of the latency of the stage
+	Max          float64            `json:"Max"`          // Maximum latency of the stage [ms]
+}
+
+// LatencyStages decomposes the latency of the transactions into successive
+// stages, from their send to their finality
+type LatencyStages struct {
+	Submission  StageLatency `json:"Submission"`  // From the send to the acknowledgement of the node
+	Inclusion   StageLatency `json:"Inclusion"`   // From the acknowledgement (or send) to the timestamp of the block including the transaction
+	Observation StageLatency `json:"Observation"` // From the timestamp of the block to the client observing it
+	Finality    StageLatency `json:"Finality"`    // From the client observing the block to it reaching the confirmation depth
+}
+
+// measured returns whether the client interfaces measured any of the stages
+func (s LatencyStages) measured() bool {
+	return s.Submission.Transactions > 0 || s.Inclusion.Transactions > 0 ||
+		s.Observation.Transactions > 0 || s.Finality.Transactions > 0
+}
+
+// stageLatency returns the distribution of the given latencies of a stage, sorting them in place
+func stageLatency(latencies []float64) StageLatency {
+	if len(latencies) == 0 {
+		return StageLatency{}
+	}
+
+	sort.Float64s(latencies)
+
+	sum := float64(0)
+	for _, v := range latencies {
+		sum += v
+	}
+
+	return StageLatency{
+		Transactions: uint(len(latencies)),
+		Average:      sum / float64(len(latencies)),
+		Percentiles:  percentilesOf(latencies),
+		Max:          latencies[len(latencies)-1],
+	}
+}
+
+// stageDuration returns the duration between the start and end of a stage in
+// ms, clamped to 0 as block timestamps have a coarser precision than the clocks
+// of the secondaries
+func stageDuration(start int64, end int64) float64 {
+	if end < start {
+		return 0
+	}
+
+	return float64(end-start) / float64(time.Millisecond)
+}
+
+// CalculateLatencyStages calculates the latency of each stage of the committed
+// transactions, from the times of the stages measured by the client interfaces.
+// A stage is only measured for the transactions whose client measured both its
+// start and end, the inclusion starting at the send if the acknowledgement of
+// the node is not measured.
+func CalculateLatencyStages(res *AggregatedResults) {
+	var submission, inclusion, observation, finality []float64
+
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Sent == 0 || tx.Committed == 0 || tx.Error != "" {
+					continue
+				}
+
+				if tx.Acknowledged > 0 {
+					submission = append(submission, stageDuration(tx.Sent, tx.Acknowledged))
+				}
+
+				if tx.Included > 0 {
+					start := tx.Sent
+					if tx.Acknowledged > 0 {
+						start = tx.Acknowledged
+					}
+					inclusion = append(inclusion, stageDuration(start, tx.Included))
+					observation = append(observation, stageDuration(tx.Included, tx.Committed))
+				}
+
+				if tx.Finalized > 0 {
+					finality = append(finality, stageDuration(tx.Committed, tx.Finalized))
+				}
+			}
+		}
+	}
+
+	res.Stages = LatencyStages{
+		Submission:  stageLatency(submission),
+		Inclusion:   stageLatency(inclusion),
+		Observation: stageLatency(observation),
+		Finality:    stageLatency(finality),
+	}
+}
@@ -0,0 +1,51 @@
+package results
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateLatencyStages(t *testing.T) {
+	s := int64(time.Second)
+	ms := int64(time.Millisecond)
+
+	res := CalculateAggregatedResults([][]Results{
+		{{
+			Transactions: []TransactionRecord{
+				// Ethereum: acknowledged, included in a block timestamped to the second and final 2 blocks later
+				{Sent: s, Acknowledged: s + 10*ms, Included: 2 * s, Committed: 2*s + 300*ms, Finalized: 6*s + 300*ms, Block: 7},
+				{Sent: s, Acknowledged: s + 30*ms, Included: s, Committed: s + 900*ms, Block: 6},
+				{Sent: s, Acknowledged: s + 20*ms, Error: ErrorTimeout},
+			},
+		}},
+		{{
+			Transactions: []TransactionRecord{
+				// Fabric: committed and final with no acknowledgement or block timestamp
+				{Sent: s, Committed: 2 * s, Finalized: 2 * s, Block: 3},
+			},
+		}},
+	})
+	CalculateLatencyStages(&res)
+
+	t.Run("submission", func(t *testing.T) {
+		if v := res.Stages.Submission; v.Transactions != 2 || v.Average != 20 || v.Max != 30 {
+			t.Errorf("unexpected submission latency %+v", v)
+		}
+	})
+
+	t.Run("inclusion and observation", func(t *testing.T) {
+		// The block timestamp precedes the acknowledgement of the second transaction
+		if v := res.Stages.Inclusion; v.Transactions != 2 || v.Max != 990 || v.Percentiles.P50 != 0 {
+			t.Errorf("unexpected inclusion latency %+v", v)
+		}
+		if v := res.Stages.Observation; v.Transactions != 2 || v.Average != 600 {
+			t.Errorf("unexpected observation latency %+v", v)
+		}
+	})
+
+	t.Run("finality", func(t *testing.T) {
+		if v := res.Stages.Finality; v.Transactions != 2 || v.Max != 4000 || v.Percentiles.P50 != 0 {
+			t.Errorf("unexpected finality latency %+v", v)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package results
+
+import (
+	"diablo-benchmark/core/configs"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SweepResult is a row of the sweep matrix report, summarising the results of a
+// single combination of the sweep parameters.
+type SweepResult struct {
+	configs.SweepPoint
+	Concurrency       int     `json:"Concurrency"`       // Maximum outstanding transactions (closed loop)
+	AverageThroughput float64 `json:"AverageThroughput"` // Average throughput reached overall
+	MaxThroughput     float64 `json:"MaximumThroughput"` // Maximum Throughput reached over time
+	AverageLatency    float64 `json:"AverageLatency"`    // Average latency across all workers and secondaries
+	MedianLatency     float64 `json:"MedianLatency"`     // Median Latency across all workers and secondaries
+	P99Latency        float64 `json:"P99Latency"`        // 99th percentile latency across all transactions
+	MaxLatency        float64 `json:"MaxLatency"`        // Maximum Latency across all workers and secondaries
+	TotalSuccess      uint    `json:"TotalSuccess"`      // Total number of successes
+	TotalFails        uint    `json:"TotalFails"`        // Total number of fails
+	AssertionsPassed  bool    `json:"AssertionsPassed"`  // Whether all assertions passed
+}
+
+// NewSweepResult summarises the aggregated results of a sweep combination
+func NewSweepResult(point configs.SweepPoint, res AggregatedResults) SweepResult {
+	return SweepResult{
+		SweepPoint:        point,
+		Concurrency:       res.Concurrency,
+		AverageThroughput: res.AverageThroughput,
+		MaxThroughput:     res.MaxThroughput,
+		AverageLatency:    res.AverageLatency,
+		MedianLatency:     res.MedianLatency,
+		P99Latency:        LatencyPercentile(&res, 99),
+		MaxLatency:        res.MaxLatency,
+		TotalSuccess:      res.TotalSuccess,
+		TotalFails:        res.TotalFails,
+		AssertionsPassed:  res.AssertionsPassed,
+	}
+}
+
+// formatFloat formats a value of the CSV reports with a fixed precision
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// writeSweepCSV writes the sweep matrix as a CSV table with one row per combination
+func writeSweepCSV(path string, sweepResults []SweepResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	err = w.Write([]string{
+		"threads", "secondaries", "tps", "concurrency",
+		"average_throughput", "max_throughput",
+		"average_latency", "median_latency", "p99_latency", "max_latency",
+		"success", "fails", "assertions_passed",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range sweepResults {
+		err = w.Write([]string{
+			strconv.Itoa(r.Threads), strconv.Itoa(r.Secondaries), strconv.Itoa(r.TPS), strconv.Itoa(r.Concurrency),
+			formatFloat(r.AverageThroughput), formatFloat(r.MaxThroughput),
+			formatFloat(r.AverageLatency), formatFloat(r.MedianLatency), formatFloat(r.P99Latency), formatFloat(r.MaxLatency),
+			strconv.FormatUint(uint64(r.TotalSuccess), 10), strconv.FormatUint(uint64(r.TotalFails), 10),
+			strconv.FormatBool(r.AssertionsPassed),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteSweepReport writes the sweep matrix report as both CSV and JSON into the
+// given results directory.
+func WriteSweepReport(sweepResults []SweepResult, resultDir string) error {
+	if !checkFileExists(resultDir) {
+		zap.L().Warn(fmt.Sprintf("Directory %s does not exist, creating it", resultDir))
+		err := os.Mkdir(resultDir, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	ts := fmt.Sprintf("%v", time.Now().Format(time.RFC3339))
+
+	err := writeSweepCSV(fmt.Sprintf("%s/%s_sweep.csv", resultDir, ts), sweepResults)
+	if err != nil {
+		return err
+	}
+
+	f, err := json.MarshalIndent(sweepResults, "", " ")
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(fmt.Sprintf("%s/%s_sweep.json", resultDir, ts), f, 0644)
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info(fmt.Sprintf("Sweep report saved in: %s/%s_sweep.csv", resultDir, ts))
+
+	return nil
+}
+
+// DisplaySweep presents the sweep matrix to stdout
+func DisplaySweep(sweepResults []SweepResult) {
+	fmt.Println()
+	fmt.Println("--------------------------")
+	fmt.Println("Sweep Complete")
+	fmt.Println("--------------------------")
+	fmt.Println(fmt.Sprintf("%8s %12s %8s %12s %18s %14s %14s", "threads", "secondaries", "tps", "concurrency", "throughput [tx/s]", "latency [ms]", "p99 [ms]"))
+	for _, r := range sweepResults {
+		fmt.Println(fmt.Sprintf("%8d %12d %8d %12d %18.3f %14.3f %14.3f", r.Threads, r.Secondaries, r.TPS, r.Concurrency, r.AverageThroughput, r.AverageLatency, r.P99Latency))
+	}
+	fmt.Println()
+}
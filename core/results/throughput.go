@@ -0,0 +1,99 @@
+package results
+
+import (
+	"time"
+)
+
+// CalculateThroughputWindows computes the throughput over time from the commit
+// times of the transactions of all secondaries, counted in windows of the given
+// size aligned on the start of the benchmark (earliest send), so that the
+// windows of all workers and secondaries cover the same period. The average
+// throughput, overall and per secondary, is the number of successful commits
+// over the span from the first send to the last commit. It replaces the
+// throughput measured by the tickers of the workers, and leaves it as is if
+// the secondaries returned no transaction records.
+func CalculateThroughputWindows(res *AggregatedResults, window time.Duration) {
+	if window <= 0 {
+		return
+	}
+
+	start, end := int64(-1), int64(-1)
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Sent > 0 && (start < 0 || tx.Sent < start) {
+					start = tx.Sent
+				}
+				if tx.Committed > end {
+					end = tx.Committed
+				}
+			}
+		}
+	}
+
+	if start < 0 || end < start {
+		return
+	}
+
+	numWindows := int((end-start)/int64(window)) + 1
+	rate := float64(time.Second) / float64(window)
+
+	total := make([]float64, numWindows)
+	perSecondary := make([][]float64, len(res.RawResults))
+	commits := make([]uint, len(res.RawResults))
+	for secondaryID, secondaryResult := range res.RawResults {
+		perSecondary[secondaryID] = make([]float64, numWindows)
+
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Committed <= 0 || tx.Error != "" {
+					continue
+				}
+
+				// Commits cannot precede the first send, unless the clocks of
+				// the secondaries drift apart
+				k := int((tx.Committed - start) / int64(window))
+				if k < 0 {
+					k = 0
+				}
+
+				total[k] += rate
+				perSecondary[secondaryID][k] += rate
+				commits[secondaryID]++
+			}
+		}
+	}
+
+	res.ThroughputWindow = float64(window) / float64(time.Millisecond)
+	res.ThroughputStart = start
+	res.TotalThroughputTimes = total
+	res.TotalThroughputSecondaryTime = perSecondary
+	for i := range res.SecondaryResults {
+		if i < len(perSecondary) {
+			res.SecondaryResults[i].ThroughputSeconds = perSecondary[i]
+		}
+	}
+
+	if span := time.Duration(end - start).Seconds(); span > 0 {
+		res.AverageThroughput = 0
+		res.AverageThroughputSecondary = make([]float64, len(commits))
+		for i, v := range commits {
+			throughput := float64(v) / span
+			res.AverageThroughput += throughput
+			res.AverageThroughputSecondary[i] = throughput
+			if i < len(res.SecondaryResults) {
+				res.SecondaryResults[i].Throughput = throughput
+			}
+		}
+	}
+
+	res.MinThroughput, res.MaxThroughput = total[0], total[0]
+	for _, v := range total {
+		if v < res.MinThroughput {
+			res.MinThroughput = v
+		}
+		if v > res.MaxThroughput {
+			res.MaxThroughput = v
+		}
+	}
+}
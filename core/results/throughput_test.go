@@ -0,0 +1,78 @@
+package results
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalculateThroughputWindows(t *testing.T) {
+	s := int64(time.Second)
+	ms := int64(time.Millisecond)
+
+	// The workers start at different times, the windows are aligned on the first send
+	res := CalculateAggregatedResults([][]Results{
+		{{
+			Transactions: []TransactionRecord{
+				{Sent: 10 * s, Committed: 10*s + 100*ms},
+				{Sent: 10 * s, Committed: 10*s + 600*ms},
+				{Sent: 10 * s, Error: ErrorTimeout},
+			},
+			Throughput: 7,
+		}},
+		{{
+			Transactions: []TransactionRecord{
+				{Sent: 10*s + 300*ms, Committed: 10*s + 400*ms},
+				{Sent: 10*s + 300*ms, Committed: 10*s + 1200*ms},
+			},
+			Throughput: 1,
+		}},
+	})
+
+	t.Run("aligned windows", func(t *testing.T) {
+		CalculateThroughputWindows(&res, 500*time.Millisecond)
+
+		expected := []float64{4, 2, 2}
+		if len(res.TotalThroughputTimes) != len(expected) {
+			t.Fatalf("expected %d windows, got %v", len(expected), res.TotalThroughputTimes)
+		}
+		for i, v := range expected {
+			if res.TotalThroughputTimes[i] != v {
+				t.Errorf("window %d: expected %v tx/s, got %v", i, v, res.TotalThroughputTimes[i])
+			}
+		}
+
+		if second := res.TotalThroughputSecondaryTime[1]; second[0] != 2 || second[1] != 0 || second[2] != 2 {
+			t.Errorf("unexpected windows of the second secondary %v", second)
+		}
+		if res.ThroughputWindow != 500 || res.ThroughputStart != 10*s {
+			t.Errorf("unexpected window %v ms starting at %d", res.ThroughputWindow, res.ThroughputStart)
+		}
+		if res.MaxThroughput != 4 || res.MinThroughput != 2 {
+			t.Errorf("unexpected max %v and min %v throughput", res.MaxThroughput, res.MinThroughput)
+		}
+	})
+
+	t.Run("average from the commits", func(t *testing.T) {
+		// The tickers of the workers disagree, 4 commits over 1.2s are kept
+		CalculateThroughputWindows(&res, 500*time.Millisecond)
+
+		if math.Abs(res.AverageThroughput-4/1.2) > 1e-9 {
+			t.Errorf("expected an average throughput of %v, got %v", 4/1.2, res.AverageThroughput)
+		}
+		for i, v := range res.SecondaryResults {
+			if math.Abs(v.Throughput-2/1.2) > 1e-9 || math.Abs(res.AverageThroughputSecondary[i]-2/1.2) > 1e-9 {
+				t.Errorf("secondary %d: expected a throughput of %v, got %v", i, 2/1.2, v.Throughput)
+			}
+		}
+	})
+
+	t.Run("no transaction records", func(t *testing.T) {
+		noRecords := CalculateAggregatedResults([][]Results{{{ThroughputSeconds: []float64{3, 5}}}})
+		CalculateThroughputWindows(&noRecords, time.Second)
+
+		if noRecords.ThroughputWindow != 0 || len(noRecords.TotalThroughputTimes) != 2 {
+			t.Errorf("expected the throughput of the workers to be kept, got %v", noRecords.TotalThroughputTimes)
+		}
+	})
+}
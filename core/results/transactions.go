@@ -0,0 +1,123 @@
+package results
+
+import (
+	"sort"
+	"time"
+)
+
+// TransactionRecord is the information recorded for each transaction of the
+// workload, in the order the worker sent them.
+type TransactionRecord struct {
+	ID        string     `json:"ID,omitempty"`       // Identifier of the transaction on the blockchain (e.g. hash)
+	Function  string     `json:"Function,omitempty"` // Function called by the transaction, empty for transfers
+	Type      string     `json:"Type,omitempty"`     // Type of the function called, "read" or "write", empty if unknown
+	Interval  int        `json:"Interval"`           // Interval of the workload the transaction belongs to
+	Scheduled int64      `json:"Scheduled"`          // Intended send time of the transaction (unix nanoseconds)
+	Sent      int64      `json:"Sent"`               // Time the worker sent the transaction (unix nanoseconds)
+	Committed int64      `json:"Committed"`          // Commit time of the transaction, 0 if not committed (unix nanoseconds)
+	Error     ErrorClass `json:"Error,omitempty"`    // Class of the error if the transaction failed
+
+	// Stages of the transaction, 0 if not measured by the client interface (unix nanoseconds)
+	Acknowledged int64  `json:"Acknowledged,omitempty"` // Time the node acknowledged the transaction
+	Included     int64  `json:"Included,omitempty"`     // Timestamp of the block including the transaction
+	Finalized    int64  `json:"Finalized,omitempty"`    // Time the block reached the confirmation depth
+	Block        uint64 `json:"Block,omitempty"`        // Number of the block including the transaction
+}
+
+// LatencySummary summarises the latencies measured from one origin
+type LatencySummary struct {
+	Average float64 `json:"Average"` // Average latency [ms]
+	Median  float64 `json:"Median"`  // Median latency [ms]
+	P99     float64 `json:"P99"`     // 99th percentile latency [ms]
+	Max     float64 `json:"Max"`     // Maximum latency [ms]
+}
+
+// IntervalLag is an interval where the workers sent transactions later than
+// scheduled by more than the lag threshold, i.e. Diablo itself was queueing.
+type IntervalLag struct {
+	Interval   int     `json:"Interval"`   // Interval of the workload
+	MaxLag     float64 `json:"MaxLag"`     // Maximum lag behind the schedule [ms]
+	AverageLag float64 `json:"AverageLag"` // Average lag behind the schedule [ms]
+	LaggedTx   uint    `json:"LaggedTx"`   // Number of transactions lagging more than the threshold
+}
+
+// summariseLatencies returns the summary of the given latencies, sorting them in place
+func summariseLatencies(latencies []float64) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+
+	sort.Float64s(latencies)
+
+	sum := float64(0)
+	for _, v := range latencies {
+		sum += v
+	}
+
+	return LatencySummary{
+		Average: sum / float64(len(latencies)),
+		Median:  getPercentile(latencies, 50),
+		P99:     getPercentile(latencies, 99),
+		Max:     latencies[len(latencies)-1],
+	}
+}
+
+// CalculateScheduleLatencies calculates the latency of the committed
+// transactions from both their scheduled and actual send times, and flags the
+// intervals where sending lagged the schedule by more than the threshold.
+// Measuring from the schedule includes the queueing within Diablo, which the
+// latency from the actual send time hides when the workers fall behind. A zero
+// threshold flags no interval, as in closed loop there is no schedule to lag.
+func CalculateScheduleLatencies(res *AggregatedResults, lagThreshold time.Duration) {
+	var fromScheduled, fromSent []float64
+
+	lags := make(map[int]*IntervalLag)
+	lagCount := make(map[int]uint)
+
+	for _, secondaryResult := range res.RawResults {
+		for _, workerResult := range secondaryResult {
+			for _, tx := range workerResult.Transactions {
+				if tx.Sent == 0 {
+					continue
+				}
+
+				lag := float64(tx.Sent-tx.Scheduled) / float64(time.Millisecond)
+				l, ok := lags[tx.Interval]
+				if !ok {
+					l = &IntervalLag{Interval: tx.Interval}
+					lags[tx.Interval] = l
+				}
+				if lag > l.MaxLag {
+					l.MaxLag = lag
+				}
+				l.AverageLag += lag
+				lagCount[tx.Interval]++
+				if lagThreshold > 0 && time.Duration(tx.Sent-tx.Scheduled) > lagThreshold {
+					l.LaggedTx++
+				}
+
+				if tx.Committed == 0 {
+					continue
+				}
+
+				fromScheduled = append(fromScheduled, float64(tx.Committed-tx.Scheduled)/float64(time.Millisecond))
+				fromSent = append(fromSent, float64(tx.Committed-tx.Sent)/float64(time.Millisecond))
+			}
+		}
+	}
+
+	res.ScheduledLatency = summariseLatencies(fromScheduled)
+	res.SentLatency = summariseLatencies(fromSent)
+
+	res.LaggedIntervals = nil
+	for interval, l := range lags {
+		l.AverageLag = l.AverageLag / float64(lagCount[interval])
+		if l.LaggedTx > 0 {
+			res.LaggedIntervals = append(res.LaggedIntervals, *l)
+		}
+	}
+
+	sort.Slice(res.LaggedIntervals, func(i, j int) bool {
+		return res.LaggedIntervals[i].Interval < res.LaggedIntervals[j].Interval
+	})
+}
@@ -0,0 +1,43 @@
+package results
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateScheduleLatencies(t *testing.T) {
+	ms := int64(time.Millisecond)
+
+	res := AggregatedResults{
+		RawResults: [][]Results{{{
+			Transactions: []TransactionRecord{
+				{Interval: 0, Scheduled: 0, Sent: 10 * ms, Committed: 110 * ms},
+				{Interval: 1, Scheduled: 1000 * ms, Sent: 1300 * ms, Committed: 1400 * ms},
+				{Interval: 1, Scheduled: 1500 * ms, Sent: 1500 * ms},
+			},
+		}}},
+	}
+
+	CalculateScheduleLatencies(&res, 100*time.Millisecond)
+
+	if res.SentLatency.Average != 100 {
+		t.Errorf("latency from send: expected average 100, got %.3f", res.SentLatency.Average)
+	}
+	if res.ScheduledLatency.Max != 400 {
+		t.Errorf("latency from schedule: expected max 400, got %.3f", res.ScheduledLatency.Max)
+	}
+
+	if len(res.LaggedIntervals) != 1 || res.LaggedIntervals[0].Interval != 1 {
+		t.Fatalf("expected only interval 1 to be flagged, got %v", res.LaggedIntervals)
+	}
+	if res.LaggedIntervals[0].MaxLag != 300 || res.LaggedIntervals[0].LaggedTx != 1 {
+		t.Errorf("unexpected lag for interval 1: %v", res.LaggedIntervals[0])
+	}
+
+	t.Run("no lag threshold", func(t *testing.T) {
+		CalculateScheduleLatencies(&res, 0)
+		if len(res.LaggedIntervals) != 0 {
+			t.Errorf("expected no interval to be flagged, got %v", res.LaggedIntervals)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -75,8 +76,8 @@ func writeResults(path string, data AggregatedResults) error {
 }
 
 // WriteResultsToFile is dedicated to bundle all result information into a given directory, writing the results to a JSON as
-// well as the containing benchmark and chain configuration files
-func WriteResultsToFile(benchConfig string, chainConfig string, results AggregatedResults, resultDir string) error {
+// well as the containing benchmark and chain configuration files, the results in each of the export formats and the HTML report
+func WriteResultsToFile(benchConfig string, chainConfig string, results AggregatedResults, resultDir string, exports []ExportFormat) error {
 	// First, check that the directory exists
 	if !checkFileExists(resultDir) {
 		zap.L().Warn(fmt.Sprintf("Directory %s does not exist, creating it", resultDir))
@@ -96,17 +97,39 @@ func WriteResultsToFile(benchConfig string, chainConfig string, results Aggregat
 
 	zap.L().Info(fmt.Sprintf("Results saved in: %s/%s_results.json", resultDir, ts))
 
+	prefix := fmt.Sprintf("%s/%s", resultDir, ts)
+	err = writeExports(prefix, results, exports)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range exports {
+		zap.L().Info(fmt.Sprintf("Results exported to: %s", exportPath(prefix, format)))
+	}
+
 	err = copyFile(benchConfig, fmt.Sprintf("%s/%s_workload.yaml", resultDir, ts))
 	if err != nil {
 		return err
 	}
 
 	err = copyFile(chainConfig, fmt.Sprintf("%s/%s_chain.yaml", resultDir, ts))
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Write the report last, it shows the configurations copied above
+	err = writeReport(prefix+"_report.html", "Diablo results "+ts, results, prefix+"_workload.yaml", prefix+"_chain.yaml")
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info(fmt.Sprintf("Report saved in: %s_report.html", prefix))
+
+	return nil
 }
 
 // Display presents the formatting to display the results to stdout.
-// TODO: future - this can be made to show graphs, and present the results in a much nicer way!
+// The graphs of the results are in the HTML report written with the results.
 func Display(results AggregatedResults) {
 
 	fmt.Println()
@@ -116,13 +139,135 @@ func Display(results AggregatedResults) {
 	fmt.Println("[*] Aggregated Stats")
 	fmt.Println(fmt.Sprintf("\t [-] Throughput [tx/sec]: %.3f [Min: %.3f | Max: %.3f]", results.AverageThroughput, results.MinThroughput, results.MaxThroughput))
 	fmt.Println(fmt.Sprintf("\t [-] Latency        [ms]: %.3f [Min: %+v | Max: %+v]", results.AverageLatency, results.MinLatency, results.MaxLatency))
+	fmt.Println(fmt.Sprintf("\t [-] Percentiles    [ms]: p50 %.3f, p75 %.3f, p90 %.3f, p95 %.3f, p99 %.3f, p99.9 %.3f",
+		results.Percentiles.P50, results.Percentiles.P75, results.Percentiles.P90, results.Percentiles.P95, results.Percentiles.P99, results.Percentiles.P999))
 
 	for i, v := range results.SecondaryResults {
 		fmt.Println(fmt.Sprintf("[*] Secondary %d Stats", i))
 		fmt.Println(fmt.Sprintf("\t [-] Throughput [tx/sec]: %.3f", v.Throughput))
 		fmt.Println(fmt.Sprintf("\t [-] Latency        [ms]: %.3f", v.AverageLatency))
+		if v.CompletionReason != "" {
+			fmt.Println(fmt.Sprintf("\t [-] Completion        : %s", v.CompletionReason))
+		}
+	}
+
+	fmt.Println("[*] Latency by origin")
+	fmt.Println(fmt.Sprintf("\t [-] From schedule [ms]: avg %.3f, median %.3f, p99 %.3f, max %.3f",
+		results.ScheduledLatency.Average, results.ScheduledLatency.Median, results.ScheduledLatency.P99, results.ScheduledLatency.Max))
+	fmt.Println(fmt.Sprintf("\t [-] From send     [ms]: avg %.3f, median %.3f, p99 %.3f, max %.3f",
+		results.SentLatency.Average, results.SentLatency.Median, results.SentLatency.P99, results.SentLatency.Max))
+
+	stages := []struct {
+		name  string
+		stage StageLatency
+	}{
+		{"Submission ", results.Stages.Submission},
+		{"Inclusion  ", results.Stages.Inclusion},
+		{"Observation", results.Stages.Observation},
+		{"Finality   ", results.Stages.Finality},
+	}
+	if results.Stages.measured() {
+		fmt.Println("[*] Latency by stage")
+	}
+	for _, v := range stages {
+		if v.stage.Transactions == 0 {
+			if results.Stages.measured() {
+				fmt.Println(fmt.Sprintf("\t [-] %s [ms]: not measured by the client interface", v.name))
+			}
+			continue
+		}
+		fmt.Println(fmt.Sprintf("\t [-] %s [ms]: avg %.3f, p50 %.3f, p90 %.3f, p99 %.3f, max %.3f (%d tx)",
+			v.name, v.stage.Average, v.stage.Percentiles.P50, v.stage.Percentiles.P90, v.stage.Percentiles.P99, v.stage.Max, v.stage.Transactions))
+	}
+
+	if len(results.LaggedIntervals) > 0 {
+		fmt.Println("[*] Intervals lagging the schedule")
+		for _, v := range results.LaggedIntervals {
+			fmt.Println(fmt.Sprintf("\t [!] Interval %d: max lag %.3f ms, average lag %.3f ms, %d tx lagging", v.Interval, v.MaxLag, v.AverageLag, v.LaggedTx))
+		}
+	}
+
+	if len(results.ErrorBreakdown) > 0 {
+		fmt.Println("[*] Errors")
+		for _, class := range sortedErrorClasses(results.ErrorBreakdown) {
+			fmt.Println(fmt.Sprintf("\t [-] %-20s: %d", class, results.ErrorBreakdown[class]))
+		}
+		for _, v := range results.IntervalErrors {
+			var counts []string
+			for _, class := range sortedErrorClasses(v.Errors) {
+				counts = append(counts, fmt.Sprintf("%s=%d", class, v.Errors[class]))
+			}
+			fmt.Println(fmt.Sprintf("\t [-] Interval %d: %s", v.Interval, strings.Join(counts, ", ")))
+		}
+	}
+
+	if len(results.FunctionBreakdown) > 0 {
+		fmt.Println("[*] Functions")
+		for _, v := range results.FunctionBreakdown {
+			displayBreakdown(v)
+		}
+	}
+
+	if len(results.TypeBreakdown) > 0 {
+		fmt.Println("[*] Function Types")
+		for _, v := range results.TypeBreakdown {
+			displayBreakdown(v)
+		}
+	}
+
+	if b := results.BlockStats; b.Blocks > 0 {
+		fmt.Println("[*] Blocks")
+		fmt.Println(fmt.Sprintf("\t [-] Blocks               : %d (%d empty)", b.Blocks, b.EmptyBlocks))
+		fmt.Println(fmt.Sprintf("\t [-] Interval         [ms]: avg %.3f, p50 %.3f, p99 %.3f, max %.3f",
+			b.Interval.Average, b.Interval.Percentiles.P50, b.Interval.Percentiles.P99, b.Interval.Max))
+		fmt.Println(fmt.Sprintf("\t [-] Transactions per block: avg %.3f, p50 %.3f, p99 %.3f, max %.3f",
+			b.TransactionsPerBlock.Average, b.TransactionsPerBlock.Percentiles.P50, b.TransactionsPerBlock.Percentiles.P99, b.TransactionsPerBlock.Max))
+		if b.Fullness.Max > 0 {
+			fmt.Println(fmt.Sprintf("\t [-] Fullness          [%%]: avg %.3f, p50 %.3f, p99 %.3f, max %.3f",
+				b.Fullness.Average, b.Fullness.Percentiles.P50, b.Fullness.Percentiles.P99, b.Fullness.Max))
+		}
+		fmt.Println(fmt.Sprintf("\t [-] Diablo transactions  : %d of %d (%.1f%%)",
+			b.DiabloTransactions, b.DiabloTransactions+b.ForeignTransactions, 100*b.DiabloFraction))
+	}
+
+	if len(results.Resources) > 0 {
+		fmt.Println("[*] Secondary Resources")
+		for i, v := range results.Resources {
+			if len(v.Samples) == 0 {
+				continue
+			}
+			fmt.Println(fmt.Sprintf("\t [-] Secondary %d: CPU avg %.1f%%, max %.1f%% (%d CPUs), RSS max %d MB, goroutines max %d, GC pause %.3f ms, network rx %d MB, tx %d MB",
+				i, v.AverageCPU, v.MaxCPU, v.NumCPU, v.MaxRSS>>20, v.MaxGoroutines, v.GCPause, v.NetRx>>20, v.NetTx>>20))
+		}
+		for _, v := range results.ResourceWarnings {
+			fmt.Println(fmt.Sprintf("\t [!] %s", v))
+		}
+	}
+
+	if results.Concurrency > 0 {
+		fmt.Println("[*] Closed Loop")
+		fmt.Println(fmt.Sprintf("\t [-] Concurrency          : %d", results.Concurrency))
+		fmt.Println(fmt.Sprintf("\t [-] Effective Concurrency: %.3f", results.EffectiveConcurrency))
+		fmt.Println(fmt.Sprintf("\t [-] Throughput    [tx/s]: %.3f", results.AverageThroughput))
+	}
+
+	if len(results.Assertions) > 0 {
+		fmt.Println("[*] Assertions")
+		for _, v := range results.Assertions {
+			status := "PASS"
+			if !v.Passed {
+				status = "FAIL"
+			}
+			fmt.Println(fmt.Sprintf("\t [%s] %s: %.3f [Threshold: %.3f]", status, v.Name, v.Actual, v.Threshold))
+		}
 	}
 
 	fmt.Println()
 
 }
+
+// displayBreakdown presents the results of a function or type of function
+func displayBreakdown(v BreakdownResults) {
+	fmt.Println(fmt.Sprintf("\t [-] %s: %d tx, %d committed, %d failed, %.3f tx/sec, latency avg %.3f ms, p50 %.3f, p90 %.3f, p99 %.3f",
+		v.Name, v.Transactions, v.Success, v.Fails, v.Throughput, v.AverageLatency, v.Percentiles.P50, v.Percentiles.P90, v.Percentiles.P99))
+}
@@ -2,12 +2,18 @@ package core
 
 import (
 	"diablo-benchmark/blockchains/clientinterfaces"
+	"diablo-benchmark/blockchains/workloadgenerators"
 	"diablo-benchmark/communication"
 	"diablo-benchmark/core/configs"
 	"diablo-benchmark/core/handlers"
+	"diablo-benchmark/core/hooks"
+	"diablo-benchmark/core/metrics"
+	"diablo-benchmark/core/results"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -21,6 +27,11 @@ type Secondary struct {
 	Blockchain      clientinterfaces.BlockchainInterface // Blockchain Interface
 	PrimaryComms    *communication.ConnClient            // Connection to the primary
 	WorkloadHandler *handlers.WorkloadHandler            // Workload Handler
+	Hooks           *hooks.Runner                        // Lifecycle hooks run between the phases of the benchmark
+	RunID           string                               // Identifier of the run sent by the primary, empty until prepared
+	SpoolDir        string                               // Directory to spool the workload to, empty keeps it in memory
+	Metrics         *metrics.SecondaryMetrics            // Metrics served to Prometheus, nil if not served
+	results         []results.Results                    // Results of the last run, nil until it completed
 }
 
 // NewSecondary creates a new secondary, performs set up for the tcp connection to primary.
@@ -34,11 +45,166 @@ func NewSecondary(chainConfig *configs.ChainConfig, benchConfig *configs.BenchCo
 
 	// Log and return, ready to go!
 	zap.L().Info("Secondary init")
-	return &Secondary{
+	s := &Secondary{
 		ChainConfig:  chainConfig,
 		BenchConfig:  benchConfig,
 		PrimaryComms: c,
-	}, nil
+	}
+	// Replaced once prepared, so that the hooks know the ID of the secondary
+	s.Hooks = s.newHookRunner()
+
+	return s, nil
+}
+
+// newHookRunner creates the runner for the lifecycle hooks that run on this
+// secondary, with the run ID of the primary once prepared
+func (s *Secondary) newHookRunner() *hooks.Runner {
+	runID := s.RunID
+	if runID == "" {
+		runID = time.Now().Format("20060102T150405")
+	}
+
+	return hooks.NewRunner(s.BenchConfig.Hooks, hooks.RunInfo{
+		RunID:           runID,
+		Role:            hooks.RoleSecondary,
+		SecondaryID:     s.ID,
+		BenchName:       s.BenchConfig.Name,
+		BenchConfigPath: s.BenchConfig.Path,
+		ChainName:       s.ChainConfig.Name,
+		ChainConfigPath: s.ChainConfig.Path,
+		Nodes:           s.ChainConfig.Nodes,
+		Secondaries:     s.BenchConfig.Secondaries,
+		Threads:         s.BenchConfig.Threads,
+		ResultsDir:      ResultsDir,
+	})
+}
+
+// writeResults writes the results of the last run to the results directory, so
+// that they can be merged with "diablo merge" if the primary fails to collect them.
+// Failures are only logged, the results are still sent to the primary.
+func (s *Secondary) writeResults() {
+	sr := results.SecondaryResults{
+		SecondaryID: s.ID,
+		Results:     s.results,
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		sr.Host = host
+	}
+
+	if len(s.WorkloadHandler.StartEnd) == 2 {
+		sr.Start, sr.End = s.WorkloadHandler.StartEnd[0], s.WorkloadHandler.StartEnd[1]
+	} else {
+		sr.Start, sr.End = time.Now(), time.Now()
+	}
+
+	path, err := results.WriteSecondaryResults(ResultsDir, sr)
+	if err != nil {
+		zap.L().Warn("failed to write the results locally",
+			zap.Error(err))
+		return
+	}
+
+	zap.L().Info(fmt.Sprintf("Results saved in: %s", path))
+}
+
+// expandWorkloadSpec decodes the workload spec sent by the primary and expands
+// it into the signed transactions of this secondary with the workload generator of the chain.
+func (s *Secondary) expandWorkloadSpec(data []byte) (workloadgenerators.SecondaryWorkload, error) {
+	spec, err := communication.DecodeWorkloadSpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Secondary != s.ID {
+		return nil, fmt.Errorf("workload spec for secondary %d sent to secondary %d", spec.Secondary, s.ID)
+	}
+
+	generatorClass, err := workloadgenerators.GetWorkloadGenerator(s.ChainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	workload, err := generatorClass.NewGenerator(s.ChainConfig, s.BenchConfig).ExpandWorkloadSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Expanded workload spec",
+		zap.Int("transactions", spec.NumTransactions()),
+		zap.Duration("duration", time.Since(start)))
+
+	return workload, nil
+}
+
+// readWorkload reads the workload of the given length sent by the primary and
+// sets up the workers with it. With a spool directory the workload is streamed
+// from the connection to the spool rather than read into memory.
+func (s *Secondary) readWorkload(workloadLen uint64) error {
+	if s.SpoolDir != "" {
+		r := s.PrimaryComms.PayloadReader(workloadLen)
+		err := s.WorkloadHandler.SpoolWorkloads(r)
+		// Keep the following commands in sync even if spooling stopped early
+		if discardErr := s.PrimaryComms.DiscardPayload(r); discardErr != nil && err == nil {
+			err = discardErr
+		}
+		if err != nil {
+			zap.L().Warn("failed to spool workload",
+				zap.String("err", err.Error()))
+			return err
+		}
+
+		zap.L().Debug("Workload spooled",
+			zap.Uint64("length", workloadLen))
+		return nil
+	}
+
+	wl, err := s.PrimaryComms.ReadSize(workloadLen)
+	if err != nil {
+		zap.L().Warn("failed to read workload bytes",
+			zap.String("err", err.Error()))
+		return err
+	}
+
+	unmarshaledWorkload, err := communication.DecodeWorkload(wl)
+	if err != nil {
+		zap.L().Warn("failed to unmarshal workload",
+			zap.String("err", err.Error()),
+			zap.Uint64("expected_length", workloadLen),
+			zap.Int("length", len(wl)))
+		return err
+	}
+
+	err = s.WorkloadHandler.ParseWorkloads(unmarshaledWorkload)
+	if err != nil {
+		zap.L().Warn("failed to parse workload",
+			zap.String("err", err.Error()))
+		return err
+	}
+
+	zap.L().Debug("Workload unmarshal OK",
+		zap.Int("Length", len(unmarshaledWorkload)),
+	)
+
+	return nil
+}
+
+// readRunID reads the run ID of the primary that follows the prepare message,
+// as its length and bytes
+func (s *Secondary) readRunID() error {
+	runIDLen, err := s.PrimaryComms.ReadFull(8)
+	if err != nil {
+		return err
+	}
+
+	runID, err := s.PrimaryComms.ReadFull(binary.BigEndian.Uint64(runIDLen))
+	if err != nil {
+		return err
+	}
+
+	s.RunID = string(runID)
+	return nil
 }
 
 // Run is the main loop that performs the receiving of commands and executes relevant actions.
@@ -71,6 +237,15 @@ func (s *Secondary) Run() {
 			s.ID = int(cmd[1])
 			s.ID = int(binary.BigEndian.Uint32(cmd[1:5]))
 			numThreads := binary.BigEndian.Uint32(cmd[5:9])
+			if err = s.readRunID(); err != nil {
+				s.PrimaryComms.ReplyERR(err.Error())
+				continue
+			}
+			// A new prepare (e.g. the next benchmark of a sweep) replaces the previous clients
+			if s.WorkloadHandler != nil {
+				s.WorkloadHandler.CloseAll()
+			}
+			s.results = nil
 			// Connect le blockchains
 			var bcis []clientinterfaces.BlockchainInterface
 			for i := uint32(0); i < numThreads; i++ {
@@ -85,9 +260,17 @@ func (s *Secondary) Run() {
 			wHandler := handlers.NewWorkloadHandler(
 				numThreads,
 				bcis,
-				s.BenchConfig.Timeout,
+				s.BenchConfig,
 			)
 
+			if s.SpoolDir != "" {
+				wHandler.SetSpoolDir(s.SpoolDir)
+			}
+
+			if s.Metrics != nil {
+				wHandler.SetObserver(s.Metrics)
+			}
+
 			s.WorkloadHandler = wHandler
 
 			err := s.WorkloadHandler.Connect(s.ChainConfig, s.ID)
@@ -99,6 +282,12 @@ func (s *Secondary) Run() {
 			zap.L().Debug("Connect and Init of workload handler and client interface OK",
 				zap.Int("ID", s.ID),
 			)
+
+			s.Hooks = s.newHookRunner()
+			if err = s.Hooks.RunPhase(configs.HookPhaseSetup); err != nil {
+				s.PrimaryComms.ReplyERR(err.Error())
+				continue
+			}
 		case communication.MsgWorkload[0]:
 			zap.L().Info("Got command from primary",
 				zap.String("CMD", "WORKLOAD"))
@@ -110,27 +299,40 @@ func (s *Secondary) Run() {
 				zap.Uint64("length", workloadLen),
 				zap.Binary("raw", cmd[1:]))
 
-			wl, err := s.PrimaryComms.ReadSize(workloadLen)
+			if err := s.readWorkload(workloadLen); err != nil {
+				s.PrimaryComms.ReplyERR(err.Error())
+				continue
+			}
+
+			if err = s.Hooks.RunPhase(configs.HookPhaseWorkload); err != nil {
+				s.PrimaryComms.ReplyERR(err.Error())
+				continue
+			}
 
+		case communication.MsgWorkloadSpec[0]:
+			zap.L().Info("Got command from primary",
+				zap.String("CMD", "WORKLOADSPEC"))
+
+			// Spec length = bytes 1-8
+			specLen := binary.BigEndian.Uint64(cmd[1:])
+
+			data, err := s.PrimaryComms.ReadSize(specLen)
 			if err != nil {
-				zap.L().Warn("failed to read workload bytes",
+				zap.L().Warn("failed to read workload spec bytes",
 					zap.String("err", err.Error()))
 				s.PrimaryComms.ReplyERR(err.Error())
 				continue
 			}
 
-			unmarshaledWorkload, err := communication.DecodeWorkload(wl)
-
+			expandedWorkload, err := s.expandWorkloadSpec(data)
 			if err != nil {
-				zap.L().Warn("failed to unmarshal workload",
-					zap.String("err", err.Error()),
-					zap.Uint64("expected_length", workloadLen),
-					zap.Int("length", len(wl)))
+				zap.L().Warn("failed to expand workload spec",
+					zap.String("err", err.Error()))
 				s.PrimaryComms.ReplyERR(err.Error())
 				continue
 			}
 
-			err = s.WorkloadHandler.ParseWorkloads(unmarshaledWorkload)
+			err = s.WorkloadHandler.ParseWorkloads(expandedWorkload)
 			if err != nil {
 				zap.L().Warn("failed to parse workload",
 					zap.String("err", err.Error()))
@@ -138,34 +340,62 @@ func (s *Secondary) Run() {
 				continue
 			}
 
-			zap.L().Debug("Workload unmarshal OK",
-				zap.Int("Length", len(unmarshaledWorkload)),
-			)
+			if err = s.Hooks.RunPhase(configs.HookPhaseWorkload); err != nil {
+				s.PrimaryComms.ReplyERR(err.Error())
+				continue
+			}
 
 		case communication.MsgRun[0]:
 			zap.L().Info("Got command from primary",
 				zap.String("CMD", "RUN"))
+			if err = s.Hooks.RunPhase(configs.HookPhaseRunStart); err != nil {
+				s.PrimaryComms.ReplyERR(err.Error())
+				continue
+			}
+			if s.Metrics != nil {
+				s.Metrics.SetSource(s.WorkloadHandler)
+			}
 			errs := s.WorkloadHandler.RunBench()
 			if errs != nil {
 				zap.L().Warn("error during bench",
-					zap.Error(err))
+					zap.Error(errs))
+				s.PrimaryComms.ReplyERR(errs.Error())
+				continue
+			}
+			if err = s.Hooks.RunPhase(configs.HookPhaseRunEnd); err != nil {
 				s.PrimaryComms.ReplyERR(err.Error())
 				continue
 			}
+			// The benchmark is over for the primary, the results are then
+			// collected and kept locally before reading the next command, so
+			// that they can be merged if the primary never asks for them
+			s.PrimaryComms.ReplyOK()
+			s.results = s.WorkloadHandler.HandleCleanup()
+			s.writeResults()
+			continue
 		case communication.MsgResults[0]:
 			zap.L().Info("Got command from primary",
 				zap.String("CMD", "RESULTS"))
-			res := s.WorkloadHandler.HandleCleanup()
+			// The results are collected at the end of the run, the workers can only be cleaned up once
+			res := s.results
+			if res == nil {
+				res = s.WorkloadHandler.HandleCleanup()
+				s.results = res
+			}
 			resBytes, err := json.Marshal(res)
 			if err != nil {
 				s.PrimaryComms.ReplyERR("failed to convert results to bytes")
 			}
-			fmt.Println(resBytes)
 			s.PrimaryComms.SendDataOK(resBytes)
+			// The results have already been replied, hook failures are only logged
+			_ = s.Hooks.RunPhase(configs.HookPhaseResults)
 		case communication.MsgFin[0]:
 			zap.L().Info("Got command from primary",
 				zap.String("CMD", "FIN"))
-			s.WorkloadHandler.CloseAll()
+			// Secondaries left out of a sweep may have never been prepared
+			if s.WorkloadHandler != nil {
+				s.WorkloadHandler.CloseAll()
+			}
 			return
 		default:
 			// Return that there was no matching command
@@ -22,6 +22,8 @@ import (
 	"diablo-benchmark/core"
 	"diablo-benchmark/core/configs"
 	"diablo-benchmark/core/configs/parsers"
+	"diablo-benchmark/core/metrics"
+	"diablo-benchmark/core/results"
 	"fmt"
 	"os"
 
@@ -152,10 +154,31 @@ func runPrimary(primaryArgs *core.PrimaryArgs) {
 
 	// Initialise the TCP server
 	m := core.InitPrimary(primaryArgs.ListenAddr, bConfig.Secondaries, wg, bConfig, cConfig)
+	m.Exports = primaryArgs.Exports
+
+	if primaryArgs.MetricsAddr != "" {
+		m.Metrics = metrics.NewPrimaryMetrics()
+		if err = m.Metrics.Serve(primaryArgs.MetricsAddr); err != nil {
+			zap.L().Error("failed to serve metrics",
+				zap.Error(err))
+			os.Exit(1)
+		}
+	}
 
 	// Run the benchmark flow
 	zap.L().Info("Primary ready, running benchmark flow")
-	m.Run()
+	err = m.Run()
+
+	// Exit with 2 if the assertions failed so that CI pipelines can tell a
+	// failed benchmark apart from a run that could not complete.
+	if err == core.ErrAssertionsFailed {
+		zap.L().Error(err.Error())
+		os.Exit(2)
+	} else if err != nil {
+		zap.L().Error("benchmark did not complete",
+			zap.Error(err))
+		os.Exit(1)
+	}
 }
 
 // Run the secondary
@@ -189,16 +212,106 @@ func runSecondary(secondaryArgs *core.SecondaryArgs) {
 		os.Exit(1)
 	}
 
+	secondary.SpoolDir = secondaryArgs.SpoolDir
+
+	if secondaryArgs.MetricsAddr != "" {
+		secondary.Metrics = metrics.NewSecondaryMetrics()
+		if err = secondary.Metrics.Serve(secondaryArgs.MetricsAddr); err != nil {
+			zap.L().Error("failed to serve metrics",
+				zap.Error(err))
+			os.Exit(1)
+		}
+	}
+
 	secondary.Run()
 }
 
+// Generate the HTML report of a results file
+func runReport(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: diablo report <results.json>\n")
+		os.Exit(1)
+	}
+
+	path, err := results.GenerateReport(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate the report: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("Report saved in: %s\n", path)
+}
+
+// Compare the results files to the first one, exiting with 2 on regressions
+func runCompare(compareArgs *core.CompareArgs, paths []string) {
+	if len(paths) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: diablo compare [--threshold=<percent>] <baseline.json> <results.json>...\n")
+		os.Exit(1)
+	}
+
+	var runs []results.AggregatedResults
+	for _, path := range paths {
+		res, err := results.LoadResults(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load results: %s\n", err.Error())
+			os.Exit(1)
+		}
+		runs = append(runs, res)
+	}
+
+	comparison := results.CompareResults(runs[0], runs[1:], compareArgs.Threshold, compareArgs.AbsoluteThreshold)
+	results.DisplayComparison(paths, comparison)
+
+	// Exit with 2 like failed assertions so that CI pipelines can catch regressions
+	if comparison.Regressions > 0 {
+		os.Exit(2)
+	}
+}
+
+// Merge the results written by the secondaries into the results of the run
+func runMerge(mergeArgs *core.MergeArgs, paths []string) {
+	if len(paths) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: diablo merge -c <bench.yaml> -cc <chain.yaml> [--export=<formats>] <secondary_results.json>...\n")
+		os.Exit(1)
+	}
+
+	mergeArgs.CheckArgs()
+
+	bConfig, err := parsers.ParseBenchConfig(mergeArgs.BenchConfigPath)
+	if err != nil {
+		zap.L().Error(err.Error())
+		os.Exit(1)
+	}
+
+	cConfig, err := parsers.ParseChainConfig(mergeArgs.ChainConfigPath)
+	if err != nil {
+		zap.L().Error(err.Error())
+		os.Exit(1)
+	}
+
+	res, err := core.MergeResults(paths, bConfig, cConfig)
+	if err != nil {
+		zap.L().Error("failed to merge the results",
+			zap.Error(err))
+		os.Exit(1)
+	}
+
+	results.Display(res)
+	err = results.WriteResultsToFile(bConfig.Path, cConfig.Path, res, core.ResultsDir, mergeArgs.Exports)
+	if err != nil {
+		zap.L().Error("Encountered error when saving results",
+			zap.Error(err))
+		os.Exit(1)
+	}
+}
+
 // Main running function
 func main() {
 	args := core.DefineArguments()
 
 	if len(os.Args) < 2 {
 		// This is going to be a primary
-		fmt.Fprintf(os.Stderr, "No subcommand given (primary/secondary), exiting!")
+		fmt.Fprintf(os.Stderr, "No subcommand given (primary/secondary/report/compare/merge), exiting!")
 		os.Exit(1)
 	} else {
 		switch os.Args[1] {
@@ -226,6 +339,19 @@ func main() {
 				os.Exit(1)
 			}
 			runSecondary(args.SecondaryArgs)
+
+		case "report":
+			runReport(os.Args[2:])
+
+		case "compare":
+			args.CompareCommand.Parse(os.Args[2:])
+			runCompare(args.CompareArgs, args.CompareCommand.Args())
+
+		case "merge":
+			args.MergeCommand.Parse(os.Args[2:])
+
+			prepareLogger("merge", zapcore.InfoLevel)
+			runMerge(args.MergeArgs, args.MergeCommand.Args())
 		}
 	}
 }
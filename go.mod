@@ -5,6 +5,7 @@ go 1.14
 require (
 	github.com/ethereum/go-ethereum v1.9.15
 	github.com/hyperledger/fabric-sdk-go v1.0.0-rc1
+	github.com/prometheus/client_golang v1.1.0
 	go.uber.org/zap v1.15.0
 	gopkg.in/yaml.v3 v3.0.0-20200601152816-913338de1bd2
 )